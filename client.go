@@ -16,6 +16,8 @@ import (
 	"github.com/df-mc/go-xsapi/v2/mpsd"
 	"github.com/df-mc/go-xsapi/v2/notification"
 	"github.com/df-mc/go-xsapi/v2/presence"
+	"github.com/df-mc/go-xsapi/v2/profile"
+	"github.com/df-mc/go-xsapi/v2/reputation"
 	"github.com/df-mc/go-xsapi/v2/rta"
 	"github.com/df-mc/go-xsapi/v2/social"
 	"github.com/df-mc/go-xsapi/v2/xal"
@@ -100,6 +102,8 @@ func (config ClientConfig) New(ctx context.Context, src TokenSource) (*Client, e
 	c.mpsd = mpsd.New(c.HTTPClient(), r, c.UserInfo(), c.Log().With("src", "mpsd"))
 	c.social = social.New(c.HTTPClient(), r, c.UserInfo(), c.Log().With("src", "social"))
 	c.presence = presence.New(c.HTTPClient(), c.UserInfo())
+	c.profile = profile.New(c.HTTPClient(), c.UserInfo())
+	c.reputation = reputation.New(c.HTTPClient(), c.UserInfo())
 	c.notification = notification.New(c.HTTPClient(), c.UserInfo(), c.Log())
 	return c, nil
 }
@@ -186,6 +190,8 @@ type Client struct {
 	mpsd         *mpsd.Client
 	social       *social.Client
 	presence     *presence.Client
+	profile      *profile.Client
+	reputation   *reputation.Client
 	notification *notification.Client
 
 	closeMu  sync.Mutex
@@ -301,6 +307,16 @@ func (c *Client) Presence() *presence.Client {
 	return c.presence
 }
 
+// Profile returns the API client for the Xbox Live Profile API.
+func (c *Client) Profile() *profile.Client {
+	return c.profile
+}
+
+// Reputation returns the API client for the Xbox Live Reputation API.
+func (c *Client) Reputation() *reputation.Client {
+	return c.reputation
+}
+
 // RTA returns the connection to Xbox Live RTA (Real-Time Activity) services.
 // If [ClientConfig.RTAMode] is [RTALazy], RTA returns nil until an operation
 // creates the connection.
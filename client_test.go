@@ -396,3 +396,116 @@ func (b *closingBody) Close() error {
 	b.closed.Store(true)
 	return b.ReadCloser.Close()
 }
+
+func TestJoinableSessionsCombinesFriendsActivitiesAndPresence(t *testing.T) {
+	userInfo := xsts.UserInfo{XUID: "1"}
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body string
+		switch {
+		case req.URL.Host == "peoplehub.xboxlive.com":
+			body = `{"people":[{"xuid":"2","gamertag":"bob"},{"xuid":"3","gamertag":"Alice"}]}`
+		case req.URL.Host == "sessiondirectory.xboxlive.com":
+			body = `{"results":[
+				{"id":"` + uuid.NewString() + `","ownerXuid":"2","relatedInfo":{"membersCount":1,"maxMembersCount":8}},
+				{"id":"` + uuid.NewString() + `","ownerXuid":"4"},
+				{"id":"` + uuid.NewString() + `","ownerXuid":"3","relatedInfo":{"closed":true}}
+			]}`
+		case req.URL.Host == "userpresence.xboxlive.com":
+			body = `[{"xuid":"2","state":"Online"}]`
+		default:
+			return nil, fmt.Errorf("unexpected request URL %s", req.URL)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})}
+	client := &Client{
+		mpsd:     mpsd.New(httpClient, nil, userInfo, nil),
+		social:   social.New(httpClient, nil, userInfo, nil),
+		presence: presence.New(httpClient, userInfo),
+	}
+
+	feed, err := client.JoinableSessions(context.Background(), uuid.New(), JoinableSessionsConfig{})
+	if err != nil {
+		t.Fatalf("JoinableSessions: %v", err)
+	}
+	sessions := feed.Sessions()
+	if len(sessions) != 2 {
+		t.Fatalf("len(sessions) = %d, want 2", len(sessions))
+	}
+	if got := sessions[0].User.GamerTag; got != "Alice" {
+		t.Fatalf("first session owner = %q, want %q", got, "Alice")
+	}
+	if sessions[0].Presence != nil {
+		t.Fatal("first session has presence, want nil")
+	}
+	if !sessions[0].Activity.RelatedInfo.Closed {
+		t.Fatal("first session is not reported as closed")
+	}
+	if sessions[1].Presence == nil || sessions[1].Presence.State != "Online" {
+		t.Fatalf("second session presence = %+v, want online", sessions[1].Presence)
+	}
+	if got := sessions[1].Activity.RelatedInfo.MembersCount; got != 1 {
+		t.Fatalf("second session members count = %d, want 1", got)
+	}
+}
+
+type stubProvider struct{}
+
+func (stubProvider) Subscribe(context.Context, *rta.Subscription) error   { return nil }
+func (stubProvider) Unsubscribe(context.Context, *rta.Subscription) error { return nil }
+
+func TestJoinableSessionsFeedCloseWaitsForOnChange(t *testing.T) {
+	userInfo := xsts.UserInfo{XUID: "1"}
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"people":[]}`)), Request: req}, nil
+	})}
+	client := &Client{
+		mpsd:     mpsd.New(httpClient, nil, userInfo, nil),
+		social:   social.New(httpClient, stubProvider{}, userInfo, nil),
+		presence: presence.New(httpClient, userInfo),
+	}
+
+	var (
+		changing = make(chan struct{})
+		release  = make(chan struct{})
+		changed  atomic.Bool
+	)
+	feed, err := client.JoinableSessions(context.Background(), uuid.New(), JoinableSessionsConfig{
+		Live:            true,
+		RefreshInterval: time.Millisecond,
+		OnChange: func([]JoinableSession) {
+			if changed.Swap(true) {
+				return
+			}
+			close(changing)
+			<-release
+		},
+	})
+	if err != nil {
+		t.Fatalf("JoinableSessions: %v", err)
+	}
+	<-changing
+
+	closed := make(chan struct{})
+	go func() {
+		_ = feed.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned while OnChange was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return after OnChange returned")
+	}
+
+	// OnChange is no longer called once Close has returned.
+	changed.Store(false)
+	time.Sleep(20 * time.Millisecond)
+	if changed.Load() {
+		t.Fatal("OnChange was called after Close returned")
+	}
+}
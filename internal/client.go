@@ -14,6 +14,10 @@ import (
 // authenticated title.
 const XBLRelyingParty = "http://xboxlive.com"
 
+// PresenceBatchLimit is the maximum number of XUIDs included in a single batch
+// presence query. Callers querying presences of more users split them into chunks.
+const PresenceBatchLimit = 1100
+
 // NewRequest creates a new HTTP request with the given context, method, URL, and body,
 // then applies any provided request options.
 func NewRequest(ctx context.Context, method, u string, reqBody io.Reader, opts []RequestOption) (*http.Request, error) {
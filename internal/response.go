@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseDetails holds the details of an unsuccessful response returned by an
+// Xbox Live service. Each API package carries them in its own ResponseError type.
+type ResponseDetails struct {
+	// Method is the HTTP request method, if available.
+	Method string
+	// URL is the HTTP request URL, if available.
+	URL string
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+	// Code is the service error code, if the response body included one.
+	Code int
+	// Description is the service error description, if present.
+	Description string
+	// Source is the service error source, if present.
+	Source string
+	// RetryAfter is the server-requested delay before retrying, if present.
+	RetryAfter time.Duration
+}
+
+// ParseResponse parses the details of an unsuccessful response. The response body is
+// read to decode the error code, description and source reported by the service. The
+// status code, request metadata and Retry-After header are preserved even if the body
+// cannot be read or decoded.
+func ParseResponse(resp *http.Response) ResponseDetails {
+	d := ResponseDetails{
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	if resp.Request != nil {
+		d.Method = resp.Request.Method
+		if resp.Request.URL != nil {
+			d.URL = resp.Request.URL.String()
+		}
+	}
+	if resp.Body == nil {
+		return d
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return d
+	}
+	var data struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+		Source      string `json:"source"`
+	}
+	if err := json.Unmarshal(body, &data); err == nil {
+		d.Code = data.Code
+		d.Description = data.Description
+		d.Source = data.Source
+	}
+	return d
+}
+
+// ParseRetryAfter parses Retry-After header values in either seconds or HTTP-date form.
+// It returns zero if the value is empty, malformed or does not describe a future delay.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+	delay := time.Until(when)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
@@ -0,0 +1,14 @@
+package internal
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	delay := ParseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if delay <= 0 || delay > time.Hour {
+		t.Fatalf("delay = %s, want within the next hour", delay)
+	}
+}
@@ -0,0 +1,271 @@
+package xsapi
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+	"github.com/df-mc/go-xsapi/v2/mpsd"
+	"github.com/df-mc/go-xsapi/v2/presence"
+	"github.com/df-mc/go-xsapi/v2/social"
+	"github.com/google/uuid"
+)
+
+// JoinableSession describes a multiplayer session published by a friend of the caller.
+type JoinableSession struct {
+	// User is the profile of the friend who owns the multiplayer session.
+	User social.User
+	// Presence is the presence of the friend. It may be nil if the presence of
+	// the friend could not be retrieved.
+	Presence *presence.Presence
+	// Activity is the activity handle advertising the multiplayer session. It can be
+	// used with [mpsd.Client.Join] to join the session. [mpsd.ActivityHandle.RelatedInfo]
+	// describes the number of members, whether the session is closed and its visibility,
+	// which callers may use to filter sessions that cannot be joined.
+	Activity mpsd.ActivityHandle
+}
+
+// JoinableSessionsConfig describes a configuration for retrieving joinable sessions
+// of friends using [Client.JoinableSessions].
+type JoinableSessionsConfig struct {
+	// Live keeps the returned [JoinableSessionsFeed] up to date until it is closed.
+	//
+	// The feed is refreshed whenever the social subscription reports a change in the
+	// caller's friend list. Since activity handles published by friends are not delivered
+	// over the MPSD subscription of the caller, the feed is also refreshed periodically
+	// using RefreshInterval.
+	Live bool
+	// RefreshInterval is the interval at which a live feed is refreshed.
+	// If zero, a default of 30 seconds is used. It has no effect unless Live is true.
+	RefreshInterval time.Duration
+	// OnChange, if non-nil, is called with the refreshed sessions each time a live
+	// feed is refreshed.
+	OnChange func(sessions []JoinableSession)
+}
+
+// JoinableSessions returns the multiplayer sessions in the service configuration identified
+// by the SCID that are published by friends of the caller, combined with the profile and
+// presence of each friend.
+//
+// It combines [social.Client.Friends], [mpsd.Client.ActivitiesForUsers] and [presence.Client.Batch],
+// splitting the requests into chunks as required by each service. If [JoinableSessionsConfig.Live]
+// is true, the returned feed is kept up to date until [JoinableSessionsFeed.Close] is called.
+func (c *Client) JoinableSessions(ctx context.Context, scid uuid.UUID, config JoinableSessionsConfig) (*JoinableSessionsFeed, error) {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Second * 30
+	}
+	f := &JoinableSessionsFeed{
+		client: c,
+		scid:   scid,
+		config: config,
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := f.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if config.Live {
+		f.refreshCh = make(chan struct{}, 1)
+		unsubscribe, err := c.social.Subscribe(ctx, joinableSessionsHandler{f})
+		if err != nil {
+			return nil, fmt.Errorf("subscribe to social notifications: %w", err)
+		}
+		f.unsubscribe = unsubscribe
+		go f.run()
+	} else {
+		close(f.done)
+	}
+	return f, nil
+}
+
+// JoinableSessionsFeed holds the joinable sessions of friends retrieved by [Client.JoinableSessions].
+// It is safe for concurrent use.
+type JoinableSessionsFeed struct {
+	client *Client
+	scid   uuid.UUID
+	config JoinableSessionsConfig
+
+	sessions   []JoinableSession
+	sessionsMu sync.RWMutex
+
+	// refreshCh is used to request a refresh of a live feed. It is nil if the
+	// feed is not live.
+	refreshCh chan struct{}
+	// unsubscribe unregisters a live feed from the social subscription. It is
+	// nil if the feed is not live.
+	unsubscribe func()
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	// done is closed once the goroutine refreshing a live feed has returned.
+	// It is closed immediately if the feed is not live.
+	done chan struct{}
+}
+
+// Sessions returns the most recently retrieved joinable sessions sorted by the
+// gamertag of their owners.
+func (f *JoinableSessionsFeed) Sessions() []JoinableSession {
+	f.sessionsMu.RLock()
+	defer f.sessionsMu.RUnlock()
+	return slices.Clone(f.sessions)
+}
+
+// Close stops a live feed from being refreshed. It is a no-op if the feed is not live.
+// Close cancels a refresh in progress and waits for it to return, so that
+// [JoinableSessionsConfig.OnChange] is no longer called once Close returns. It must
+// therefore not be called from OnChange.
+func (f *JoinableSessionsFeed) Close() error {
+	f.closeOnce.Do(func() {
+		if f.unsubscribe != nil {
+			f.unsubscribe()
+		}
+		close(f.closed)
+	})
+	<-f.done
+	return nil
+}
+
+// requestRefresh schedules a refresh of a live feed. Multiple requests made while a
+// refresh is pending are coalesced into one.
+func (f *JoinableSessionsFeed) requestRefresh() {
+	select {
+	case f.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// run refreshes a live feed until it is closed.
+func (f *JoinableSessionsFeed) run() {
+	defer close(f.done)
+	t := time.NewTicker(f.config.RefreshInterval)
+	defer t.Stop()
+
+	// Refreshes in progress are cancelled once the feed is closed.
+	base, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	go func() {
+		select {
+		case <-f.closed:
+			cancelBase()
+		case <-base.Done():
+		}
+	}()
+	for {
+		select {
+		case <-f.closed:
+			return
+		case <-t.C:
+		case <-f.refreshCh:
+		}
+
+		ctx, cancel := context.WithTimeout(base, time.Second*15)
+		err := f.refresh(ctx)
+		cancel()
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				f.client.Log().Error("error refreshing joinable sessions", "err", err)
+			}
+			continue
+		}
+		if f.config.OnChange != nil {
+			f.config.OnChange(f.Sessions())
+		}
+	}
+}
+
+// refresh retrieves the joinable sessions of friends and replaces the sessions held by the feed.
+func (f *JoinableSessionsFeed) refresh(ctx context.Context) error {
+	friends, err := f.client.social.Friends(ctx)
+	if err != nil {
+		return fmt.Errorf("list friends: %w", err)
+	}
+	users := make(map[string]social.User, len(friends))
+	xuids := make([]string, 0, len(friends))
+	for _, friend := range friends {
+		users[friend.XUID] = friend
+		xuids = append(xuids, friend.XUID)
+	}
+
+	var activities []mpsd.ActivityHandle
+	for chunk := range slices.Chunk(xuids, activityQueryLimit) {
+		handles, err := f.client.mpsd.ActivitiesForUsers(ctx, f.scid, chunk)
+		if err != nil {
+			return fmt.Errorf("query activities: %w", err)
+		}
+		for _, handle := range handles {
+			if _, ok := users[handle.OwnerXUID]; ok {
+				activities = append(activities, handle)
+			}
+		}
+	}
+
+	owners := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		owners = append(owners, activity.OwnerXUID)
+	}
+	slices.Sort(owners)
+	owners = slices.Compact(owners)
+	presences := make(map[string]*presence.Presence, len(owners))
+	for chunk := range slices.Chunk(owners, internal.PresenceBatchLimit) {
+		batch, err := f.client.presence.Batch(ctx, presence.BatchRequest{
+			XUIDs: chunk,
+			Depth: presence.DepthAll,
+		})
+		if err != nil {
+			return fmt.Errorf("query presences: %w", err)
+		}
+		for _, p := range batch {
+			if p != nil {
+				presences[p.XUID] = p
+			}
+		}
+	}
+
+	sessions := make([]JoinableSession, 0, len(activities))
+	for _, activity := range activities {
+		sessions = append(sessions, JoinableSession{
+			User:     users[activity.OwnerXUID],
+			Presence: presences[activity.OwnerXUID],
+			Activity: activity,
+		})
+	}
+	slices.SortStableFunc(sessions, func(a, b JoinableSession) int {
+		return cmp.Compare(strings.ToLower(a.User.GamerTag), strings.ToLower(b.User.GamerTag))
+	})
+
+	f.sessionsMu.Lock()
+	f.sessions = sessions
+	f.sessionsMu.Unlock()
+	return nil
+}
+
+// activityQueryLimit is the maximum number of XUIDs included in a single
+// activity handle query.
+const activityQueryLimit = 100
+
+// joinableSessionsHandler is a [social.SubscriptionHandler] that requests a refresh
+// of a live JoinableSessionsFeed when the caller's friend list changes.
+type joinableSessionsHandler struct {
+	*JoinableSessionsFeed
+}
+
+// HandleSocialNotification implements [social.SubscriptionHandler.HandleSocialNotification].
+func (h joinableSessionsHandler) HandleSocialNotification(string, []string) {
+	select {
+	case <-h.closed:
+	default:
+		h.requestRefresh()
+	}
+}
+
+// HandleIncomingFriendRequestCountChange implements [social.SubscriptionHandler.HandleIncomingFriendRequestCountChange].
+func (joinableSessionsHandler) HandleIncomingFriendRequestCountChange(int) {}
+
+// HandleSubscriptionLost implements [social.SubscriptionHandler.HandleSubscriptionLost].
+func (joinableSessionsHandler) HandleSubscriptionLost() {}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/df-mc/go-xsapi/v2/internal"
@@ -48,26 +51,159 @@ func (c *Client) ActivitiesForUsers(ctx context.Context, scid uuid.UUID, xuids [
 // unique per service configuration and is shared across platforms. For example, PC and
 // Android builds of a cross-platform game use the same title ID. Note that this value is
 // not necessarily the same as the title ID of the currently-authenticated title.
+//
+// The returned error may be matched against [ErrPrivacyBlocked] or [ErrThrottled] using [errors.Is].
+// Use [Session.InviteWithOptions] with [InviteOptions.PermissionChecker] to check whether the
+// caller is allowed to invite the user before the invite is sent.
 func (s *Session) Invite(ctx context.Context, xuid, titleID string, opts ...internal.RequestOption) (*InviteHandle, error) {
-	var handle *InviteHandle
-	if err := internal.Do(ctx, s.client.client, http.MethodPost, endpoint.JoinPath("handles").String(), inviteHandle{
+	return s.InviteWithOptions(ctx, xuid, InviteOptions{TitleID: titleID}, opts...)
+}
+
+// InviteWithOptions invites the user identified by the XUID to the multiplayer session
+// using the options. It behaves like [Session.Invite], but the invite handle can be given
+// a context, and the permission of the caller is checked before the invite is sent if
+// [InviteOptions.PermissionChecker] is set. [InviteOptions.Concurrency] is ignored.
+//
+// The returned error may be matched against [ErrPrivacyBlocked] or [ErrThrottled] using
+// [errors.Is], or against the errors returned by [InviteOptions.PermissionChecker], if any.
+func (s *Session) InviteWithOptions(ctx context.Context, xuid string, options InviteOptions, opts ...internal.RequestOption) (*InviteHandle, error) {
+	return s.invite(ctx, xuid, options.attributes(), options.PermissionChecker, opts)
+}
+
+// InviteOptions describes options for inviting users with [Session.InviteWithOptions]
+// or [Session.InviteMany].
+type InviteOptions struct {
+	// TitleID is the title ID associated with the invites. See [Session.Invite]
+	// for details on how the value is used.
+	TitleID string
+	// ContextStringID is the optional ID used to activate the invite handles.
+	ContextStringID string
+	// Context is the optional, title-specific context associated with the invite handles.
+	Context string
+
+	// PermissionChecker is consulted before each invite is sent, so that users who are not
+	// allowed to play with the caller are not invited. If nil, no permission is checked.
+	PermissionChecker InvitePermissionChecker
+
+	// Concurrency is the maximum number of invites sent concurrently by [Session.InviteMany].
+	// If zero, a default of 4 is used.
+	Concurrency int
+}
+
+// attributes returns the attributes of the invite handles created using the options.
+func (options InviteOptions) attributes() InviteAttributes {
+	return InviteAttributes{
+		TitleID:         options.TitleID,
+		ContextStringID: options.ContextStringID,
+		Context:         options.Context,
+	}
+}
+
+// InviteResult describes the outcome of inviting a single user with [Session.InviteMany].
+type InviteResult struct {
+	// Handle is the invite handle created for the user. It is nil if Err is non-nil.
+	Handle *InviteHandle
+	// Err is the error that occurred while inviting the user. It may be matched
+	// against [ErrPrivacyBlocked] or [ErrThrottled] using [errors.Is], or against
+	// the errors returned by [InviteOptions.PermissionChecker], if any.
+	Err error
+}
+
+// InvitePermissionChecker checks whether the caller is allowed to invite a user to a
+// multiplayer session before the invite is sent. A [social.Client] may be used as an
+// InvitePermissionChecker to validate the PlayMultiplayer permission of the caller.
+//
+// [social.Client]: https://pkg.go.dev/github.com/df-mc/go-xsapi/v2/social#Client
+type InvitePermissionChecker interface {
+	// CheckInvitePermission returns a non-nil error if the caller is not allowed to
+	// invite the user identified by the XUID, or if the permission could not be checked.
+	CheckInvitePermission(ctx context.Context, xuid string) error
+}
+
+// InviteMany invites all users identified by the XUIDs to the multiplayer session.
+//
+// Invites are sent concurrently, bounded by [InviteOptions.Concurrency]. The returned map
+// contains an [InviteResult] for each unique XUID, holding either the created [InviteHandle]
+// or the error that occurred while inviting the user. A failure to invite one user does not
+// prevent the remaining users from being invited.
+func (s *Session) InviteMany(ctx context.Context, xuids []string, options InviteOptions, opts ...internal.RequestOption) map[string]InviteResult {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	attributes := options.attributes()
+
+	var (
+		results   = make(map[string]InviteResult, len(xuids))
+		resultsMu sync.Mutex
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, concurrency)
+	)
+	// Each unique XUID is only invited once.
+	unique := slices.Clone(xuids)
+	slices.Sort(unique)
+	for _, xuid := range slices.Compact(unique) {
+		wg.Go(func() {
+			var result InviteResult
+			select {
+			case sem <- struct{}{}:
+				result.Handle, result.Err = s.invite(ctx, xuid, attributes, options.PermissionChecker, opts)
+				<-sem
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+			}
+			resultsMu.Lock()
+			results[xuid] = result
+			resultsMu.Unlock()
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// invite is the shared implementation of [Session.InviteWithOptions] and [Session.InviteMany].
+// It invites the user identified by the XUID using the attributes, after checking the
+// permission of the caller using the checker if it is non-nil.
+func (s *Session) invite(ctx context.Context, xuid string, attributes InviteAttributes, checker InvitePermissionChecker, opts []internal.RequestOption) (*InviteHandle, error) {
+	if s.readOnly {
+		return nil, ErrReadOnly
+	}
+	if checker != nil {
+		if err := checker.CheckInvitePermission(ctx, xuid); err != nil {
+			return nil, fmt.Errorf("mpsd: check invite permission for %s: %w", xuid, err)
+		}
+	}
+	req, err := internal.WithJSONBody(ctx, http.MethodPost, endpoint.JoinPath("handles").String(), inviteHandle{
 		Type:             "invite",
 		SessionReference: s.ref,
 		Version:          1,
 		InvitedXUID:      xuid,
-		InviteAttributes: InviteAttributes{
-			TitleID: titleID,
-		},
-	}, &handle, append(opts,
+		InviteAttributes: attributes,
+	}, append(opts,
 		internal.RequestHeader("Content-Type", "application/json"),
 		internal.ContractVersion(contractVersion),
-	)); err != nil {
+	))
+	if err != nil {
+		return nil, fmt.Errorf("make request: %w", err)
+	}
+	resp, err := s.client.client.Do(req)
+	if err != nil {
 		return nil, err
 	}
-	if handle == nil {
-		return nil, errors.New("mpsd: invalid invite response")
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		var handle *InviteHandle
+		if err := json.NewDecoder(resp.Body).Decode(&handle); err != nil {
+			return nil, fmt.Errorf("decode response body: %w", err)
+		}
+		if handle == nil {
+			return nil, errors.New("mpsd: invalid invite response")
+		}
+		return handle, nil
+	default:
+		return nil, responseError(resp)
 	}
-	return handle, nil
 }
 
 // inviteHandle is the wire representation used to invite a user to the
@@ -254,11 +390,14 @@ type ActivityHandleRelatedInfo struct {
 	// multiplayer session.
 	MaxMembersCount uint32 `json:"maxMembersCount"`
 
+	// MembersCount is the number of members currently in the multiplayer session.
+	MembersCount uint32 `json:"membersCount"`
+
 	// PostedTime is the time at which the multiplayer session was created.
 	PostedTime time.Time `json:"postedTime"`
 
 	// Visibility specifies the visibility of the multiplayer session.
-	Visibility string `json:"visibility"`
+	Visibility SessionVisibility `json:"visibility"`
 }
 
 // searchRequestPeople specifies whose perspective is used when searching
@@ -0,0 +1,153 @@
+package mpsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+// ErrNotMember is returned by [Client.Attach] when the caller is not a member of
+// the multiplayer session.
+var ErrNotMember = errors.New("mpsd: caller is not a member of the session")
+
+// Attach loads an existing multiplayer session identified by the reference that the
+// caller is already a member of, such as a session published or joined before the
+// process has been restarted.
+//
+// Like sessions returned from [Client.Publish] or [Client.Join], the returned Session
+// is registered to the Client to receive notifications from the RTA subscription. The
+// connection ID of the caller in the session is updated to the current RTA connection
+// ID, and the activity handle for the session is published again, so that hosting a
+// session can survive redeploys. Unlike [Client.Publish] and [Client.Join], the caller
+// is never removed from the session if Attach fails, so that Attach may be retried.
+//
+// If the session is already tracked by the Client, the existing Session is returned.
+// Concurrent calls for the same reference wait for the first call to complete and
+// return its result.
+// An error wrapping [ErrNotMember] is returned if the caller is not a member of the session.
+// Make sure to call [Session.Close] to leave the session when it is no longer needed.
+func (c *Client) Attach(ctx context.Context, ref SessionReference, opts ...internal.RequestOption) (*Session, error) {
+	key := ref.URL().String()
+	c.sessionsMu.Lock()
+	// The in-flight calls are checked first, as a Session being attached is
+	// registered to the Client before it has been bound.
+	if call, ok := c.attaching[key]; ok {
+		c.sessionsMu.Unlock()
+		select {
+		case <-call.done:
+			return call.s, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s, ok := c.sessions[key]; ok {
+		c.sessionsMu.Unlock()
+		return s, nil
+	}
+	call := &attachCall{done: make(chan struct{})}
+	if c.attaching == nil {
+		c.attaching = make(map[string]*attachCall)
+	}
+	c.attaching[key] = call
+	c.sessionsMu.Unlock()
+
+	call.s, call.err = c.attach(ctx, ref, opts)
+
+	c.sessionsMu.Lock()
+	delete(c.attaching, key)
+	c.sessionsMu.Unlock()
+	close(call.done)
+	return call.s, call.err
+}
+
+// attachCall is an in-flight call to [Client.Attach] that concurrent calls for the same
+// reference wait on.
+type attachCall struct {
+	done chan struct{}
+	s    *Session
+	err  error
+}
+
+// attach loads the session identified by ref and binds it to the Client. If binding
+// fails, the Session is only closed locally and the caller remains a member.
+func (c *Client) attach(ctx context.Context, ref SessionReference, opts []internal.RequestOption) (*Session, error) {
+	req, err := internal.NewRequest(ctx, http.MethodGet, ref.URL().String(), nil, append(opts,
+		internal.RequestHeader("Accept", "application/json"),
+		internal.ContractVersion(contractVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("make request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var d SessionDescription
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return nil, fmt.Errorf("decode response body: %w", err)
+	}
+	if !hasMember(d, c.userInfo.XUID) {
+		return nil, fmt.Errorf("%w: %s", ErrNotMember, ref.URL())
+	}
+
+	s := c.newSession(ref, d, resp.Header.Get("ETag"))
+	if existing, ok := c.trackSession(s); !ok {
+		// The session has been published or joined concurrently while it was being retrieved.
+		return existing, nil
+	}
+	if err := s.writeActivity(ctx); err != nil {
+		err = fmt.Errorf("write activity handle: %w", err)
+		s.detach(err)
+		return nil, err
+	}
+	if err := c.reconcileSessionConnection(ctx, s); err != nil {
+		err = fmt.Errorf("update session %s connection ID: %w", s.Reference().URL(), err)
+		s.detach(fmt.Errorf("%w: %w", ErrReconcileFailed, err))
+		return nil, err
+	}
+	return s, nil
+}
+
+// detach closes s locally with the cause without leaving the multiplayer session, so that
+// the caller remains a member. s is unregistered from the Client if it has been registered.
+func (s *Session) detach(cause error) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	s.closeLocked(cause)
+}
+
+// trackSession registers s to the Client unless a Session with the same reference is
+// already registered, in which case the registered Session is returned with false.
+// The check and the registration are made atomically under the sessions lock.
+func (c *Client) trackSession(s *Session) (*Session, bool) {
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+	key := s.ref.URL().String()
+	if existing, ok := c.sessions[key]; ok {
+		return existing, false
+	}
+	c.sessions[key] = s
+	return s, true
+}
+
+// hasMember reports whether d contains a non-reserved member identified by the XUID.
+func hasMember(d SessionDescription, xuid string) bool {
+	for _, member := range d.Members {
+		if member == nil || member.Reserved || member.Constants == nil || member.Constants.System == nil {
+			continue
+		}
+		if member.Constants.System.XUID == xuid {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/df-mc/go-xsapi/v2/internal"
 	"github.com/df-mc/go-xsapi/v2/rta"
 	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+	"github.com/google/uuid"
 )
 
 // New returns a new [Client] using the provided components.
@@ -26,6 +27,8 @@ func New(client *http.Client, conn rta.Provider, userInfo xsts.UserInfo, log *sl
 		log:      log,
 
 		sessions: make(map[string]*Session),
+
+		invitePollPending: make(chan struct{}, 1),
 	}
 	c.subscription = rta.NewSubscription(resourceURI, &subscriptionHandler{
 		Client: c,
@@ -60,6 +63,31 @@ type Client struct {
 
 	sessions   map[string]*Session
 	sessionsMu sync.RWMutex
+	// attaching holds the in-flight calls to [Client.Attach] by the URL of the
+	// session reference. It is guarded by sessionsMu.
+	attaching map[string]*attachCall
+
+	// inviteHandler is the InviteHandler registered via [Client.HandleInvites].
+	// It is nil if no handler has been registered.
+	inviteHandler InviteHandler
+	// seenInvites holds the IDs of invite handles that have already been
+	// delivered to inviteHandler.
+	seenInvites map[uuid.UUID]struct{}
+	// inviteGeneration is incremented whenever inviteHandler is replaced, so that
+	// a poll started for a previous handler does not deliver to the new one.
+	inviteGeneration uint64
+	// invitesMu guards inviteHandler, seenInvites and inviteGeneration.
+	invitesMu sync.Mutex
+	// pollInvitesMu serializes polling for invites, so that invites are delivered
+	// to inviteHandler in order and at most once.
+	pollInvitesMu sync.Mutex
+	// stopInvites stops the background polling for invites started by
+	// [Client.HandleInvites]. It is nil if polling is not running. It is
+	// guarded by invitesMu.
+	stopInvites context.CancelFunc
+	// invitePollPending is signalled to request the background polling to
+	// query invites. It has a buffer of one so that requests are coalesced.
+	invitePollPending chan struct{}
 }
 
 // SessionByReference looks up for a multiplayer session identified by the reference.
@@ -93,6 +121,9 @@ func (c *Client) Close() error {
 // It unsubscribes from the RTA service if any subscription is present on the Client.
 // It is recommended to use the client-set's [github.com/df-mc/go-xsapi.Client.CloseContext] method.
 func (c *Client) CloseContext(ctx context.Context) error {
+	c.invitesMu.Lock()
+	c.stopInvitePolling()
+	c.invitesMu.Unlock()
 	if c.subscription.Active() {
 		if err := c.rta.Unsubscribe(ctx, c.subscription); err != nil {
 			return fmt.Errorf("mpsd: unsubscribe: %w", err)
@@ -2,8 +2,11 @@ package mpsd
 
 import (
 	"encoding/json"
+	"maps"
 	"net/url"
 	"slices"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -44,6 +47,20 @@ type SessionDescription struct {
 	// A member may modify only their own MemberDescription. Use of "me" is
 	// equivalent to specifying the caller's own member ID.
 	Members map[string]*MemberDescription `json:"members,omitempty"`
+
+	// Branch is a unique identifier for the current branch of the session. A new
+	// branch is started when the session is recreated, such as after it has been
+	// deleted and published again. It is only present in responses from the directory.
+	Branch uuid.UUID `json:"branch,omitzero"`
+
+	// ChangeNumber identifies the version of the session within its Branch. It is
+	// incremented each time the session is changed in the directory. It is only
+	// present in responses from the directory.
+	ChangeNumber uint64 `json:"changeNumber,omitzero"`
+
+	// Initializing describes the current initialization episode, if the session
+	// is initializing. It is only present in responses from the directory.
+	Initializing *SessionInitializing `json:"initializing,omitempty"`
 }
 
 // SessionProperties contains mutable properties associated with a multiplayer session.
@@ -160,12 +177,10 @@ type SessionConstantsSystem struct {
 	MaxMembersCount uint32 `json:"maxMembersCount,omitempty"`
 
 	// Capabilities defines the capabilities supported by the session.
-	//
-	// The format and semantics of this field are currently undefined.
-	Capabilities json.RawMessage `json:"capabilities,omitempty"`
+	Capabilities *SessionCapabilities `json:"capabilities,omitempty"`
 
 	// Visibility specifies the visibility level of the session.
-	Visibility string `json:"visibility,omitempty"`
+	Visibility SessionVisibility `json:"visibility,omitempty"`
 
 	// Initiators contains the XUIDs of users who initiated the session.
 	Initiators []string `json:"initiators,omitempty"`
@@ -187,32 +202,205 @@ type SessionConstantsSystem struct {
 	// session may remain empty before being deleted.
 	SessionEmptyTimeout uint64 `json:"sessionEmptyTimeout,omitempty"`
 
-	// Metrics is currently unknown, seems to be used for QoS measurement.
-	Metrics json.RawMessage `json:"metrics,omitempty"`
+	// Metrics specifies the QoS metrics measured between members during
+	// initialization of the session.
+	Metrics *Metrics `json:"metrics,omitempty"`
 
 	// MemberInitialization indicates that members are expected to perform
 	// initialization after session creation.
 	//
 	// Initialization stages and timeouts are tracked automatically, including
 	// initial Quality of Service (QoS) measurements when metrics are specified.
-	MemberInitialization json.RawMessage `json:"memberInitialization,omitempty"`
+	MemberInitialization *MemberInitialization `json:"memberInitialization,omitempty"`
 
 	// PeerToPeerRequirements defines QoS requirements for peer-to-peer
 	// connections between session members.
-	PeerToPeerRequirements json.RawMessage `json:"peerToPeerRequirements,omitempty"`
+	PeerToPeerRequirements *PeerToPeerRequirements `json:"peerToPeerRequirements,omitempty"`
 
 	// PeerToHostRequirements defines QoS requirements for connections between
 	// host candidates and session members.
-	PeerToHostRequirements json.RawMessage `json:"peerToHostRequirements,omitempty"`
+	PeerToHostRequirements *PeerToHostRequirements `json:"peerToHostRequirements,omitempty"`
 
 	// MeasurementServerAddresses contains the server endpoints to be evaluated
-	// for QoS measurements.
-	MeasurementServerAddresses json.RawMessage `json:"measurementServerAddresses,omitempty"`
+	// for QoS measurements, keyed by the name of each server.
+	MeasurementServerAddresses map[string]MeasurementServerAddress `json:"measurementServerAddresses,omitempty"`
 
 	// CloudComputePackage contains Cloud Compute package constants for the session.
 	CloudComputePackage json.RawMessage `json:"cloudComputePackage,omitempty"`
 }
 
+// SessionCapabilities defines the capabilities supported by a multiplayer session.
+type SessionCapabilities struct {
+	// Connectivity indicates that the session supports connectivity between members,
+	// and that members must have a valid [MemberPropertiesSystem.Connection] to become
+	// active.
+	Connectivity bool `json:"connectivity,omitempty"`
+
+	// SuppressPresenceActivityCheck disables the check that members are actively playing
+	// the title, as determined by their presence, before becoming active.
+	SuppressPresenceActivityCheck bool `json:"suppressPresenceActivityCheck,omitempty"`
+
+	// Gameplay indicates that the session represents actual gameplay rather than
+	// a lobby, and is used to populate recent players.
+	Gameplay bool `json:"gameplay,omitempty"`
+
+	// Large indicates that the session supports more than 100 members. Large
+	// sessions do not support connectivity or QoS initialization, and only a
+	// subset of the members is listed in the session.
+	Large bool `json:"large,omitempty"`
+
+	// ConnectionRequiredForActiveMembers indicates that a member must have a valid
+	// connection to become active. Members become inactive when their connection is lost.
+	ConnectionRequiredForActiveMembers bool `json:"connectionRequiredForActiveMembers,omitempty"`
+
+	// UserAuthorizationStyle indicates that the session supports calls from platforms
+	// that authorize requests on behalf of users rather than devices.
+	UserAuthorizationStyle bool `json:"userAuthorizationStyle,omitempty"`
+
+	// CrossPlay indicates that the session allows members on different platforms.
+	CrossPlay bool `json:"crossPlay,omitempty"`
+
+	// Searchable indicates that the session can be found using search handles.
+	Searchable bool `json:"searchable,omitempty"`
+
+	// HasOwners indicates that the session has owners, who can modify the session
+	// on behalf of other members.
+	HasOwners bool `json:"hasOwners,omitempty"`
+}
+
+// SessionVisibility specifies the visibility level of a multiplayer session.
+type SessionVisibility string
+
+const (
+	// SessionVisibilityPrivate indicates that the session is only readable by its
+	// members and cannot be joined without a reservation.
+	SessionVisibilityPrivate SessionVisibility = "private"
+
+	// SessionVisibilityVisible indicates that the session is readable by non-members,
+	// subject to the read restriction, but cannot be joined without a reservation.
+	SessionVisibilityVisible SessionVisibility = "visible"
+
+	// SessionVisibilityOpen indicates that the session can be read and joined by
+	// non-members, subject to the read and join restrictions.
+	SessionVisibilityOpen SessionVisibility = "open"
+)
+
+// Metrics specifies the QoS metrics measured between members while the
+// multiplayer session is initializing.
+type Metrics struct {
+	// Latency indicates that the latency between members is measured.
+	Latency bool `json:"latency,omitempty"`
+
+	// BandwidthDown indicates that the downstream bandwidth between members is measured.
+	BandwidthDown bool `json:"bandwidthDown,omitempty"`
+
+	// BandwidthUp indicates that the upstream bandwidth between members is measured.
+	BandwidthUp bool `json:"bandwidthUp,omitempty"`
+
+	// Custom indicates that title-defined measurements are performed.
+	Custom bool `json:"custom,omitempty"`
+}
+
+// MemberInitialization describes how members initialize a multiplayer session after it
+// has been created. Members with [MemberConstantsSystem.Initialize] set to true are
+// included in the initialization episode.
+type MemberInitialization struct {
+	// JoinTimeout is the duration, in milliseconds, that the members of the
+	// initialization episode may take to become active.
+	JoinTimeout uint64 `json:"joinTimeout,omitempty"`
+
+	// MeasurementTimeout is the duration, in milliseconds, that the members of the
+	// initialization episode may take to upload their QoS measurements.
+	MeasurementTimeout uint64 `json:"measurementTimeout,omitempty"`
+
+	// EvaluationTimeout is the duration, in milliseconds, that the title may take
+	// to evaluate the measurements when ExternalEvaluation is true.
+	EvaluationTimeout uint64 `json:"evaluationTimeout,omitempty"`
+
+	// ExternalEvaluation indicates that the measurements are evaluated by the title
+	// rather than by the directory.
+	ExternalEvaluation bool `json:"externalEvaluation,omitempty"`
+
+	// MembersNeededToStart is the number of members that must succeed initialization
+	// for the session to be initialized. The default value is 2.
+	MembersNeededToStart uint32 `json:"membersNeededToStart,omitempty"`
+}
+
+// PeerToPeerRequirements defines QoS requirements for peer-to-peer connections
+// between the members of a multiplayer session.
+type PeerToPeerRequirements struct {
+	// LatencyMaximum is the maximum latency, in milliseconds, allowed between members.
+	LatencyMaximum uint64 `json:"latencyMaximum,omitempty"`
+
+	// BandwidthMinimum is the minimum bandwidth, in kilobits per second, required
+	// between members.
+	BandwidthMinimum uint64 `json:"bandwidthMinimum,omitempty"`
+}
+
+// PeerToHostRequirements defines QoS requirements for connections between host
+// candidates and the members of a multiplayer session.
+type PeerToHostRequirements struct {
+	// LatencyMaximum is the maximum latency, in milliseconds, allowed between
+	// the host and members.
+	LatencyMaximum uint64 `json:"latencyMaximum,omitempty"`
+
+	// BandwidthDownMinimum is the minimum downstream bandwidth, in kilobits per
+	// second, required from the host to members.
+	BandwidthDownMinimum uint64 `json:"bandwidthDownMinimum,omitempty"`
+
+	// BandwidthUpMinimum is the minimum upstream bandwidth, in kilobits per
+	// second, required from members to the host.
+	BandwidthUpMinimum uint64 `json:"bandwidthUpMinimum,omitempty"`
+
+	// HostSelectionMetric specifies the metric used to select the host among the
+	// host candidates. Possible values are defined in this package with the
+	// HostSelectionMetric* prefix.
+	HostSelectionMetric string `json:"hostSelectionMetric,omitempty"`
+}
+
+const (
+	// HostSelectionMetricBandwidthUp selects the host with the highest upstream bandwidth.
+	HostSelectionMetricBandwidthUp = "bandwidthup"
+	// HostSelectionMetricBandwidthDown selects the host with the highest downstream bandwidth.
+	HostSelectionMetricBandwidthDown = "bandwidthdown"
+	// HostSelectionMetricBandwidth selects the host with the highest bandwidth.
+	HostSelectionMetricBandwidth = "bandwidth"
+	// HostSelectionMetricLatency selects the host with the lowest latency.
+	HostSelectionMetricLatency = "latency"
+)
+
+// MeasurementServerAddress describes a server endpoint evaluated for QoS measurements.
+type MeasurementServerAddress struct {
+	// SecureDeviceAddress is the base64-decoded secure device address of the server.
+	SecureDeviceAddress []byte `json:"secureDeviceAddress,omitempty"`
+}
+
+// SessionInitializing describes the initialization episode of a multiplayer session
+// that is currently initializing.
+type SessionInitializing struct {
+	// Stage is the current stage of the initialization. Possible values are defined
+	// in this package with the InitializationStage* prefix.
+	Stage string `json:"stage,omitempty"`
+
+	// StageStartTime is the time at which the current stage was started.
+	StageStartTime time.Time `json:"stageStartTime,omitzero"`
+
+	// Episode is the number of the current initialization episode. Members whose
+	// [MemberDescription.InitializationEpisode] matches this value take part in it.
+	Episode uint32 `json:"episode,omitempty"`
+}
+
+const (
+	// InitializationStageJoining indicates that members are joining the session.
+	InitializationStageJoining = "joining"
+	// InitializationStageMeasuring indicates that members are performing QoS measurements.
+	InitializationStageMeasuring = "measuring"
+	// InitializationStageEvaluating indicates that the measurements are evaluated by the title.
+	InitializationStageEvaluating = "evaluating"
+	// InitializationStageFailed indicates that the initialization has failed.
+	InitializationStageFailed = "failed"
+)
+
 // MemberDescription describes a member participating in a multiplayer session.
 //
 // A member description consists of immutable constants, which are fixed once
@@ -231,6 +419,80 @@ type MemberDescription struct {
 	// These properties may be updated at any time, but only by the member
 	// to whom they belong.
 	Properties *MemberProperties `json:"properties,omitempty"`
+
+	// Reserved indicates whether the member is a reservation that has not
+	// yet been claimed by the reserved user joining the session.
+	//
+	// It is set by the directory and is ignored when committing changes.
+	Reserved bool `json:"reserved,omitempty"`
+
+	// InitializationEpisode is the number of the initialization episode that the member
+	// takes part in. It is zero if the member does not take part in any initialization
+	// episode. It is set by the directory and is ignored when committing changes.
+	InitializationEpisode uint32 `json:"initializationEpisode,omitempty"`
+
+	// InitializationFailure is the reason why the member failed the initialization, such as
+	// "latency", "bandwidthDown", "bandwidthUp", "network", "group" or "timeout". It is set by
+	// the directory and is ignored when committing changes.
+	InitializationFailure string `json:"initializationFailure,omitempty"`
+
+	// DeviceToken is the device token of the member. It can be compared against
+	// [SessionPropertiesSystem.Host] to determine the host of the session. It is set
+	// by the directory and is ignored when committing changes.
+	DeviceToken string `json:"deviceToken,omitempty"`
+}
+
+// Status returns the status of the member in the multiplayer session.
+func (m MemberDescription) Status() MemberStatus {
+	switch {
+	case m.Reserved:
+		return MemberStatusReserved
+	case m.Properties == nil || m.Properties.System == nil:
+		return MemberStatusInactive
+	case m.Properties.System.Active:
+		return MemberStatusActive
+	case m.Properties.System.Ready:
+		return MemberStatusReady
+	default:
+		return MemberStatusInactive
+	}
+}
+
+// MemberStatus describes the status of a member in a multiplayer session.
+type MemberStatus uint8
+
+const (
+	// MemberStatusNone indicates that the member is not present in the session.
+	// It is used in [MemberChange] to describe members that joined or left the session.
+	MemberStatusNone MemberStatus = iota
+	// MemberStatusReserved indicates that a slot is reserved for the member, but
+	// the reserved user has not yet joined the session.
+	MemberStatusReserved
+	// MemberStatusInactive indicates that the member has joined the session but
+	// is currently inactive.
+	MemberStatusInactive
+	// MemberStatusReady indicates that the member is ready but not yet active.
+	MemberStatusReady
+	// MemberStatusActive indicates that the member is active in the session.
+	MemberStatusActive
+)
+
+// String returns a human-readable name of the MemberStatus.
+func (s MemberStatus) String() string {
+	switch s {
+	case MemberStatusNone:
+		return "none"
+	case MemberStatusReserved:
+		return "reserved"
+	case MemberStatusInactive:
+		return "inactive"
+	case MemberStatusReady:
+		return "ready"
+	case MemberStatusActive:
+		return "active"
+	default:
+		return "MemberStatus(" + strconv.Itoa(int(s)) + ")"
+	}
 }
 
 // MemberProperties contains mutable properties for a member in a multiplayer session.
@@ -297,6 +559,34 @@ type MemberPropertiesSystem struct {
 	// The value is base64-encoded and contains device and connection information
 	// for session hosting. This field is not used by most modern games.
 	SecureDeviceAddress []byte `json:"secureDeviceAddress,omitempty"`
+
+	// Measurements contains the QoS measurements between the member and other members,
+	// keyed by the label of each measured member. It is uploaded while the session is
+	// initializing in the [InitializationStageMeasuring] stage.
+	Measurements map[string]Measurement `json:"measurements,omitempty"`
+
+	// ServerMeasurements contains the QoS measurements between the member and the servers
+	// listed in [SessionConstantsSystem.MeasurementServerAddresses], keyed by the name of
+	// each server.
+	ServerMeasurements map[string]ServerMeasurement `json:"serverMeasurements,omitempty"`
+}
+
+// Measurement describes the QoS measured between two members of a multiplayer session.
+type Measurement struct {
+	// Latency is the measured latency in milliseconds.
+	Latency uint64 `json:"latency,omitempty"`
+	// BandwidthDown is the measured downstream bandwidth in kilobits per second.
+	BandwidthDown uint64 `json:"bandwidthDown,omitempty"`
+	// BandwidthUp is the measured upstream bandwidth in kilobits per second.
+	BandwidthUp uint64 `json:"bandwidthUp,omitempty"`
+	// Custom contains title-defined measurements.
+	Custom json.RawMessage `json:"custom,omitempty"`
+}
+
+// ServerMeasurement describes the QoS measured between a member and a measurement server.
+type ServerMeasurement struct {
+	// Latency is the measured latency in milliseconds.
+	Latency uint64 `json:"latency,omitempty"`
 }
 
 // MemberPropertiesSystemSubscription specifies which portions in the multiplayer session
@@ -377,13 +667,19 @@ func cloneSessionConstants(in *SessionConstants) *SessionConstants {
 	}
 	if in.System != nil {
 		system := *in.System
-		system.Capabilities = slices.Clone(in.System.Capabilities)
+		system.Capabilities = clonePointer(in.System.Capabilities)
 		system.Initiators = slices.Clone(in.System.Initiators)
-		system.Metrics = slices.Clone(in.System.Metrics)
-		system.MemberInitialization = slices.Clone(in.System.MemberInitialization)
-		system.PeerToPeerRequirements = slices.Clone(in.System.PeerToPeerRequirements)
-		system.PeerToHostRequirements = slices.Clone(in.System.PeerToHostRequirements)
-		system.MeasurementServerAddresses = slices.Clone(in.System.MeasurementServerAddresses)
+		system.Metrics = clonePointer(in.System.Metrics)
+		system.MemberInitialization = clonePointer(in.System.MemberInitialization)
+		system.PeerToPeerRequirements = clonePointer(in.System.PeerToPeerRequirements)
+		system.PeerToHostRequirements = clonePointer(in.System.PeerToHostRequirements)
+		if in.System.MeasurementServerAddresses != nil {
+			system.MeasurementServerAddresses = make(map[string]MeasurementServerAddress, len(in.System.MeasurementServerAddresses))
+			for name, address := range in.System.MeasurementServerAddresses {
+				address.SecureDeviceAddress = slices.Clone(address.SecureDeviceAddress)
+				system.MeasurementServerAddresses[name] = address
+			}
+		}
 		system.CloudComputePackage = slices.Clone(in.System.CloudComputePackage)
 		out.System = &system
 	}
@@ -414,7 +710,12 @@ func cloneMemberDescription(in *MemberDescription) *MemberDescription {
 	if in == nil {
 		return nil
 	}
-	out := &MemberDescription{}
+	out := &MemberDescription{
+		Reserved:              in.Reserved,
+		InitializationEpisode: in.InitializationEpisode,
+		InitializationFailure: in.InitializationFailure,
+		DeviceToken:           in.DeviceToken,
+	}
 	if in.Constants != nil {
 		out.Constants = &MemberConstants{
 			Custom: slices.Clone(in.Constants.Custom),
@@ -431,6 +732,14 @@ func cloneMemberDescription(in *MemberDescription) *MemberDescription {
 		if in.Properties.System != nil {
 			system := *in.Properties.System
 			system.SecureDeviceAddress = slices.Clone(in.Properties.System.SecureDeviceAddress)
+			if in.Properties.System.Measurements != nil {
+				system.Measurements = make(map[string]Measurement, len(in.Properties.System.Measurements))
+				for label, measurement := range in.Properties.System.Measurements {
+					measurement.Custom = slices.Clone(measurement.Custom)
+					system.Measurements[label] = measurement
+				}
+			}
+			system.ServerMeasurements = maps.Clone(in.Properties.System.ServerMeasurements)
 			if in.Properties.System.Subscription != nil {
 				subscription := *in.Properties.System.Subscription
 				subscription.ChangeTypes = slices.Clone(in.Properties.System.Subscription.ChangeTypes)
@@ -441,3 +750,13 @@ func cloneMemberDescription(in *MemberDescription) *MemberDescription {
 	}
 	return out
 }
+
+// clonePointer returns a shallow copy of the value pointed to by in, or nil if in is nil.
+// It is used for cloning structs that only contain fields of value types.
+func clonePointer[T any](in *T) *T {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
@@ -0,0 +1,103 @@
+package mpsd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+var (
+	// ErrThrottled matches responses that indicate the caller should wait before retrying.
+	ErrThrottled = errors.New("mpsd: throttled")
+	// ErrPrivacyBlocked matches responses indicating that a handle, such as an invite, could
+	// not be created due to the privacy settings or enforcement restrictions of the caller
+	// or the target user. The directory does not document a service error code for this, so
+	// it matches the signal of the handles endpoint instead: a 403 Forbidden response to a
+	// POST request creating a handle. Other 403 Forbidden responses, such as those for a
+	// session the caller cannot access, do not match it. The service error code and
+	// description of the response, if any, are reported in [ResponseError].
+	ErrPrivacyBlocked = errors.New("mpsd: blocked by privacy settings")
+	// ErrConflict matches responses indicating that a conditional write was rejected
+	// because the multiplayer session has been modified since the ETag was observed.
+	ErrConflict = errors.New("mpsd: session was modified concurrently")
+)
+
+// ResponseError carries details of an unsuccessful response returned by the
+// Multiplayer Session Directory (MPSD).
+type ResponseError struct {
+	// Method is the HTTP request method, if available.
+	Method string
+	// URL is the HTTP request URL, if available.
+	URL string
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+	// Code is the service error code, if the response body included one.
+	Code int
+	// Description is the service error description, if present.
+	Description string
+	// Source is the service error source, if present.
+	Source string
+	// RetryAfter is the server-requested delay before retrying, if present.
+	RetryAfter time.Duration
+}
+
+// Error implements error by formatting e as a MPSD response failure.
+func (e *ResponseError) Error() string {
+	prefix := ""
+	if e.Method != "" && e.URL != "" {
+		prefix = e.Method + " " + e.URL + ": "
+	}
+	if e.Code != 0 && e.Description != "" {
+		return fmt.Sprintf("%smpsd: request failed: status=%d code=%d description=%q", prefix, e.StatusCode, e.Code, e.Description)
+	}
+	if e.Code != 0 {
+		return fmt.Sprintf("%smpsd: request failed: status=%d code=%d", prefix, e.StatusCode, e.Code)
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%smpsd: request failed: status=%d retry_after=%s", prefix, e.StatusCode, e.RetryAfter)
+	}
+	return fmt.Sprintf("%smpsd: request failed: status=%d", prefix, e.StatusCode)
+}
+
+// Is implements errors.Is matching for MPSD error categories.
+func (e *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrThrottled:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrPrivacyBlocked:
+		return e.StatusCode == http.StatusForbidden && e.Method == http.MethodPost && isHandlesURL(e.URL)
+	case ErrConflict:
+		return e.StatusCode == http.StatusPreconditionFailed
+	default:
+		return false
+	}
+}
+
+// responseError builds a ResponseError from an unsuccessful MPSD response.
+func responseError(resp *http.Response) error {
+	d := internal.ParseResponse(resp)
+	return &ResponseError{
+		Method:      d.Method,
+		URL:         d.URL,
+		StatusCode:  d.StatusCode,
+		Code:        d.Code,
+		Description: d.Description,
+		Source:      d.Source,
+		RetryAfter:  d.RetryAfter,
+	}
+}
+
+// isHandlesURL reports whether rawURL locates to the endpoint for creating handles
+// in the directory.
+func isHandlesURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, endpoint.Host) && strings.Trim(u.Path, "/") == "handles"
+}
@@ -0,0 +1,213 @@
+package mpsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+var (
+	// ErrMemberNotFound is returned by [Session] methods when the referenced member
+	// is not present in the cached session state.
+	ErrMemberNotFound = errors.New("mpsd: member not found")
+	// ErrInvalidRestriction is returned when a join or read restriction is not one
+	// of the SessionRestriction* constants defined in this package.
+	ErrInvalidRestriction = errors.New("mpsd: invalid session restriction")
+	// ErrTooManyMembers is returned when a change would reference more members than
+	// allowed by [SessionConstantsSystem.MaxMembersCount].
+	ErrTooManyMembers = errors.New("mpsd: too many members")
+)
+
+// RemoveMember removes the member identified by label from the multiplayer session.
+//
+// It is typically used by the host of the session to kick other members. The special
+// label "me" cannot be used with RemoveMember; use [Session.Close] to leave the session instead.
+// An error wrapping [ErrMemberNotFound] is returned if no member with the label exists
+// in the cached session state.
+func (s *Session) RemoveMember(ctx context.Context, label string, opts ...internal.RequestOption) error {
+	if label == "me" {
+		return errors.New("mpsd: cannot remove the caller using RemoveMember, use Close instead")
+	}
+	if _, ok := s.Member(label); !ok {
+		return fmt.Errorf("%w: %q", ErrMemberNotFound, label)
+	}
+	return s.commit(ctx, sessionPatch{
+		Members: map[string]*MemberDescription{
+			// Set the member to nil to remove it from the multiplayer session.
+			label: nil,
+		},
+	}, opts)
+}
+
+// RemoveMemberByXUID removes the member identified by their XUID from the multiplayer session.
+// It behaves like [Session.RemoveMember] using the label of the member found in the
+// cached session state.
+func (s *Session) RemoveMemberByXUID(ctx context.Context, xuid string, opts ...internal.RequestOption) error {
+	for label, member := range s.Members() {
+		if member.Constants != nil && member.Constants.System != nil && member.Constants.System.XUID == xuid {
+			return s.RemoveMember(ctx, label, opts...)
+		}
+	}
+	return fmt.Errorf("%w: xuid %s", ErrMemberNotFound, xuid)
+}
+
+// SetClosed updates whether the multiplayer session is closed. When closed, the session
+// cannot be joined regardless of its visibility, join restriction or available capacity.
+func (s *Session) SetClosed(ctx context.Context, closed bool, opts ...internal.RequestOption) error {
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{Closed: &closed}, opts)
+}
+
+// SetLocked updates whether the membership of the multiplayer session is locked. When locked,
+// members who leave may rejoin the session, but no new members may take their place.
+func (s *Session) SetLocked(ctx context.Context, locked bool, opts ...internal.RequestOption) error {
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{Locked: &locked}, opts)
+}
+
+// SetJoinRestriction updates who may join the multiplayer session. The restriction must be
+// one of the SessionRestriction* constants, otherwise [ErrInvalidRestriction] is returned.
+func (s *Session) SetJoinRestriction(ctx context.Context, restriction string, opts ...internal.RequestOption) error {
+	if err := validateRestriction(restriction); err != nil {
+		return err
+	}
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{JoinRestriction: restriction}, opts)
+}
+
+// SetReadRestriction updates who may read the multiplayer session before joining. The restriction
+// must be one of the SessionRestriction* constants, otherwise [ErrInvalidRestriction] is returned.
+func (s *Session) SetReadRestriction(ctx context.Context, restriction string, opts ...internal.RequestOption) error {
+	if err := validateRestriction(restriction); err != nil {
+		return err
+	}
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{ReadRestriction: restriction}, opts)
+}
+
+// SetKeywords replaces the keywords associated with the multiplayer session.
+// Passing an empty slice clears all keywords.
+func (s *Session) SetKeywords(ctx context.Context, keywords []string, opts ...internal.RequestOption) error {
+	keywords = slices.Clone(keywords)
+	if keywords == nil {
+		keywords = []string{}
+	}
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{Keywords: &keywords}, opts)
+}
+
+// SetTurn updates the members that currently have the turn in a turn-based session.
+// Each value must be the numerical label of a member present in the cached session
+// state, and the number of members cannot exceed [SessionConstantsSystem.MaxMembersCount].
+// Passing an empty slice clears the turn.
+func (s *Session) SetTurn(ctx context.Context, turn []uint32, opts ...internal.RequestOption) error {
+	if err := s.validateTurn(turn); err != nil {
+		return err
+	}
+	turn = slices.Clone(turn)
+	if turn == nil {
+		turn = []uint32{}
+	}
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{Turn: &turn}, opts)
+}
+
+// SetServerConnectionStringCandidates replaces the ordered list of connection strings
+// that may be used to connect to a game server hosting the multiplayer session.
+func (s *Session) SetServerConnectionStringCandidates(ctx context.Context, candidates []string, opts ...internal.RequestOption) error {
+	if candidates == nil {
+		candidates = []string{}
+	}
+	b, err := json.Marshal(candidates)
+	if err != nil {
+		return fmt.Errorf("encode server connection string candidates: %w", err)
+	}
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{ServerConnectionStringCandidates: b}, opts)
+}
+
+// SetHost updates the device token of the host of the multiplayer session.
+// Passing an empty string clears the host.
+func (s *Session) SetHost(ctx context.Context, deviceToken string, opts ...internal.RequestOption) error {
+	return s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{Host: &deviceToken}, opts)
+}
+
+// commitSystemProperties commits changes to the system properties of the multiplayer session.
+func (s *Session) commitSystemProperties(ctx context.Context, system sessionPropertiesSystemPatch, opts []internal.RequestOption) error {
+	return s.commit(ctx, sessionPatch{
+		Properties: &sessionPropertiesPatch{
+			System: &system,
+		},
+	}, opts)
+}
+
+// validateTurn reports an error if turn references a member that is not present in the
+// cached session state, or if it contains more members than allowed by the session constants.
+func (s *Session) validateTurn(turn []uint32) error {
+	if limit := s.maxMembersCount(); limit != 0 && uint32(len(turn)) > limit {
+		return fmt.Errorf("%w: turn contains %d members, maximum is %d", ErrTooManyMembers, len(turn), limit)
+	}
+	for _, id := range turn {
+		if _, ok := s.Member(strconv.FormatUint(uint64(id), 10)); !ok {
+			return fmt.Errorf("%w: %d", ErrMemberNotFound, id)
+		}
+	}
+	return nil
+}
+
+// maxMembersCount returns the maximum number of members allowed in the session,
+// or zero if the cached session state does not specify one.
+func (s *Session) maxMembersCount() uint32 {
+	constants := s.Constants()
+	if constants.System == nil {
+		return 0
+	}
+	return constants.System.MaxMembersCount
+}
+
+// validateRestriction reports an error wrapping [ErrInvalidRestriction] if restriction
+// is not one of the SessionRestriction* constants.
+func validateRestriction(restriction string) error {
+	switch restriction {
+	case SessionRestrictionNone, SessionRestrictionLocal, SessionRestrictionFollowed:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidRestriction, restriction)
+	}
+}
+
+type (
+	// sessionPatch is the wire representation of partial changes committed to a
+	// multiplayer session.
+	//
+	// Unlike [SessionDescription], it only encodes the fields that are explicitly
+	// set, so that boolean and list properties can be reset to their zero values
+	// without overwriting unrelated properties.
+	sessionPatch struct {
+		// Properties contains changes to the properties of the session.
+		Properties *sessionPropertiesPatch `json:"properties,omitempty"`
+		// Members contains changes to the members of the session. A nil value
+		// removes the member identified by the key.
+		Members map[string]*MemberDescription `json:"members,omitempty"`
+	}
+
+	// sessionPropertiesPatch contains changes to the properties of a session.
+	sessionPropertiesPatch struct {
+		// System contains changes to the system-defined properties.
+		System *sessionPropertiesSystemPatch `json:"system,omitempty"`
+		// Custom replaces the title-defined properties if non-nil.
+		Custom json.RawMessage `json:"custom,omitempty"`
+	}
+
+	// sessionPropertiesSystemPatch contains changes to the system-defined
+	// properties of a session. Nil fields are left unchanged.
+	sessionPropertiesSystemPatch struct {
+		Keywords                         *[]string       `json:"keywords,omitempty"`
+		Turn                             *[]uint32       `json:"turn,omitempty"`
+		JoinRestriction                  string          `json:"joinRestriction,omitempty"`
+		ReadRestriction                  string          `json:"readRestriction,omitempty"`
+		Closed                           *bool           `json:"closed,omitempty"`
+		Locked                           *bool           `json:"locked,omitempty"`
+		Host                             *string         `json:"host,omitempty"`
+		ServerConnectionStringCandidates json.RawMessage `json:"serverConnectionStringCandidates,omitempty"`
+		InitializationSucceeded          *bool           `json:"initializationSucceeded,omitempty"`
+	}
+)
@@ -0,0 +1,326 @@
+package mpsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Measurer performs the QoS measurements required while a multiplayer session is
+// initializing. It can be registered on a Session via [Session.Initialize].
+type Measurer interface {
+	// MeasureServers measures the QoS between the caller and each server listed in
+	// [SessionConstantsSystem.MeasurementServerAddresses]. The returned measurements
+	// are keyed by the name of each server. It is only called if the session lists
+	// one or more measurement servers.
+	MeasureServers(ctx context.Context, servers map[string]MeasurementServerAddress) (map[string]ServerMeasurement, error)
+
+	// MeasurePeers measures the QoS between the caller and each member taking part in
+	// the same initialization episode, according to the metrics specified by the session.
+	// The peers and the returned measurements are keyed by the label of each member. It is
+	// only called if the session specifies [SessionConstantsSystem.Metrics] and there are
+	// one or more other members in the episode.
+	MeasurePeers(ctx context.Context, peers map[string]MemberDescription, metrics Metrics) (map[string]Measurement, error)
+}
+
+// Evaluator may be implemented by a [Measurer] to evaluate the measurements of an
+// initialization episode for sessions whose template enables [MemberInitialization.ExternalEvaluation].
+type Evaluator interface {
+	Measurer
+
+	// Evaluate is called when the stage reaches [InitializationStageEvaluating] in an episode
+	// that the caller takes part in. The members of the episode, including the measurements
+	// they have uploaded, are keyed by the label of each member. Evaluate reports whether the
+	// initialization succeeded, which is written to [SessionPropertiesSystem.InitializationSucceeded].
+	// If an error is returned, the initialization is failed.
+	Evaluate(ctx context.Context, members map[string]MemberDescription) (bool, error)
+}
+
+// ErrEvaluationUnsupported is reported in [InitializationResult.Err] if the session requires
+// external evaluation, but the Measurer passed to [Session.Initialize] does not implement
+// [Evaluator]. The initialization is failed instead of waiting for the evaluation to time out.
+var ErrEvaluationUnsupported = errors.New("mpsd: external evaluation requires an Evaluator")
+
+// InitializationHandler receives the result of an initialization episode that the
+// caller has taken part in. It can be registered on a Session via [Session.Initialize].
+type InitializationHandler interface {
+	// HandleInitialization is called once the initialization episode has finished.
+	HandleInitialization(session *Session, result InitializationResult)
+}
+
+// NopInitializationHandler is a no-op implementation of [InitializationHandler].
+type NopInitializationHandler struct{}
+
+// HandleInitialization implements [InitializationHandler.HandleInitialization].
+func (NopInitializationHandler) HandleInitialization(*Session, InitializationResult) {}
+
+// InitializationResult describes the result of an initialization episode.
+type InitializationResult struct {
+	// Episode is the number of the initialization episode.
+	Episode uint32
+	// Succeeded reports whether the session has been initialized successfully.
+	Succeeded bool
+	// Failure is the reason why the caller failed the initialization, as reported
+	// in [MemberDescription.InitializationFailure]. It is empty if Succeeded is true.
+	Failure string
+	// Err is the error that occurred while measuring or uploading the measurements
+	// of the caller, or while evaluating them, if any.
+	Err error
+}
+
+// Initialize opts in to taking part in the initialization of the multiplayer session.
+//
+// Sessions whose template specifies [SessionConstantsSystem.MemberInitialization] go through
+// the stages described by the InitializationStage* constants after members with
+// [MemberConstantsSystem.Initialize] set join the session. Once registered, the Session follows
+// the initialization stage as it is synchronized: when the stage reaches [InitializationStageMeasuring]
+// in an episode that the caller takes part in, m is used to measure the QoS to measurement servers
+// and peers, and the results are written into the member properties of the caller. Once the episode
+// finishes, h is notified of whether the initialization succeeded.
+//
+// If the template enables [MemberInitialization.ExternalEvaluation], the measurements are evaluated
+// by m once the stage reaches [InitializationStageEvaluating], which requires m to implement
+// [Evaluator]. Otherwise, the initialization is failed with [ErrEvaluationUnsupported]. Passing a
+// nil Measurer disables initialization. If h is nil, [NopInitializationHandler] is used.
+func (s *Session) Initialize(m Measurer, h InitializationHandler) {
+	if h == nil {
+		h = NopInitializationHandler{}
+	}
+	s.initMu.Lock()
+	if m == nil {
+		s.initialization = nil
+	} else {
+		s.initialization = &initializer{measurer: m, handler: h}
+	}
+	s.initMu.Unlock()
+
+	s.advanceInitialization()
+}
+
+// initializer holds the state of the initialization of a Session.
+type initializer struct {
+	measurer Measurer
+	handler  InitializationHandler
+
+	// episode is the initialization episode that the caller currently takes part in,
+	// or zero if the caller is not taking part in any episode.
+	episode uint32
+	// measured is the initialization episode for which measurements have been started.
+	measured uint32
+	// evaluated is the initialization episode for which the evaluation has been started.
+	evaluated uint32
+	// err is the error that occurred while measuring or evaluating the current episode, if any.
+	err error
+}
+
+// advanceInitialization compares the initialization stage of the cached session state
+// with the state of the initializer registered on s, starting measurements or reporting
+// the result of an initialization episode as needed. It is a no-op if [Session.Initialize]
+// has not been called. It is called whenever the session has been synchronized.
+func (s *Session) advanceInitialization() {
+	s.initMu.Lock()
+	defer s.initMu.Unlock()
+	state := s.initialization
+	if state == nil {
+		return
+	}
+
+	label, member, ok := s.self()
+	initializing, active := s.Initializing()
+	if ok && active && member.InitializationEpisode != 0 && member.InitializationEpisode == initializing.Episode {
+		if state.episode != initializing.Episode {
+			state.episode, state.err = initializing.Episode, nil
+		}
+		switch initializing.Stage {
+		case InitializationStageMeasuring:
+			if state.measured != state.episode {
+				state.measured = state.episode
+				go s.measure(state, label, state.episode)
+			}
+			return
+		case InitializationStageEvaluating:
+			if state.evaluated != state.episode && s.externalEvaluation() {
+				state.evaluated = state.episode
+				go s.evaluate(state, state.episode)
+			}
+			return
+		case InitializationStageFailed:
+		default:
+			return
+		}
+	}
+	if state.episode == 0 {
+		return
+	}
+
+	// The episode that the caller has taken part in has finished.
+	result := InitializationResult{
+		Episode: state.episode,
+		Failure: member.InitializationFailure,
+		Err:     state.err,
+	}
+	if !active || initializing.Stage != InitializationStageFailed {
+		properties := s.Properties()
+		result.Succeeded = result.Failure == "" && properties.System != nil && properties.System.InitializationSucceeded
+	}
+	state.episode, state.err = 0, nil
+	go state.handler.HandleInitialization(s, result)
+}
+
+// measure performs the measurements of the caller identified by the label for the episode
+// using the Measurer and uploads them into the member properties of the caller.
+func (s *Session) measure(state *initializer, label string, episode uint32) {
+	constants := s.Constants()
+	timeout := time.Second * 15
+	var (
+		servers map[string]MeasurementServerAddress
+		metrics *Metrics
+	)
+	if system := constants.System; system != nil {
+		servers, metrics = system.MeasurementServerAddresses, system.Metrics
+		if system.MemberInitialization != nil && system.MemberInitialization.MeasurementTimeout != 0 {
+			timeout = time.Duration(system.MemberInitialization.MeasurementTimeout) * time.Millisecond
+		}
+	}
+	peers := make(map[string]MemberDescription)
+	for peerLabel, member := range s.Members() {
+		if peerLabel != label && member.InitializationEpisode == episode {
+			peers[peerLabel] = member
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context(), timeout)
+	defer cancel()
+	var system memberMeasurementsPatch
+	err := func() (err error) {
+		if len(servers) > 0 {
+			system.ServerMeasurements, err = state.measurer.MeasureServers(ctx, servers)
+			if err != nil {
+				return fmt.Errorf("measure servers: %w", err)
+			}
+		}
+		if metrics != nil && len(peers) > 0 {
+			system.Measurements, err = state.measurer.MeasurePeers(ctx, peers, *metrics)
+			if err != nil {
+				return fmt.Errorf("measure peers: %w", err)
+			}
+		}
+		if system.Measurements == nil && system.ServerMeasurements == nil {
+			return nil
+		}
+		return s.commit(ctx, initializationPatch{
+			Members: map[string]memberPatch{
+				"me": {Properties: memberPropertiesPatch{System: system}},
+			},
+		}, nil)
+	}()
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, context.Canceled) {
+		s.log.Error("error measuring QoS for session initialization", slog.Any("error", err), slog.Any("episode", episode))
+	}
+	s.initMu.Lock()
+	if state.episode == episode {
+		state.err = err
+	}
+	s.initMu.Unlock()
+}
+
+// externalEvaluation reports whether the template of the session enables external
+// evaluation of the measurements.
+func (s *Session) externalEvaluation() bool {
+	system := s.Constants().System
+	return system != nil && system.MemberInitialization != nil && system.MemberInitialization.ExternalEvaluation
+}
+
+// evaluate evaluates the measurements of the members in the episode using the Measurer if
+// it implements [Evaluator], and writes the result into the session properties. If the
+// measurements cannot be evaluated, the initialization is failed.
+func (s *Session) evaluate(state *initializer, episode uint32) {
+	timeout := time.Second * 15
+	if system := s.Constants().System; system != nil && system.MemberInitialization != nil && system.MemberInitialization.EvaluationTimeout != 0 {
+		timeout = time.Duration(system.MemberInitialization.EvaluationTimeout) * time.Millisecond
+	}
+	members := make(map[string]MemberDescription)
+	for label, member := range s.Members() {
+		if member.InitializationEpisode == episode {
+			members[label] = member
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context(), timeout)
+	defer cancel()
+	var (
+		succeeded bool
+		err       error
+	)
+	if evaluator, ok := state.measurer.(Evaluator); ok {
+		succeeded, err = evaluator.Evaluate(ctx, members)
+		if err != nil {
+			err = fmt.Errorf("evaluate measurements: %w", err)
+		}
+	} else {
+		err = ErrEvaluationUnsupported
+	}
+	if err != nil {
+		// The error is recorded before writing the result, as the episode may already
+		// have finished once the session has been updated with the response.
+		succeeded = false
+		s.failEvaluation(state, episode, err)
+	}
+	if err := s.commitSystemProperties(ctx, sessionPropertiesSystemPatch{InitializationSucceeded: &succeeded}, nil); err != nil {
+		s.failEvaluation(state, episode, fmt.Errorf("write evaluation result: %w", err))
+	}
+}
+
+// failEvaluation records the error that occurred while evaluating the measurements of the
+// episode so that it is reported once the episode has finished.
+func (s *Session) failEvaluation(state *initializer, episode uint32, err error) {
+	if !errors.Is(err, context.Canceled) {
+		s.log.Error("error evaluating QoS for session initialization", slog.Any("error", err), slog.Any("episode", episode))
+	}
+	s.initMu.Lock()
+	if state.episode == episode {
+		state.err = errors.Join(state.err, err)
+	}
+	s.initMu.Unlock()
+}
+
+// self returns the label and the cached description of the member of the caller.
+func (s *Session) self() (string, MemberDescription, bool) {
+	for label, member := range s.Members() {
+		if member.Constants != nil && member.Constants.System != nil && member.Constants.System.XUID == s.client.userInfo.XUID {
+			return label, member, true
+		}
+	}
+	return "", MemberDescription{}, false
+}
+
+type (
+	// initializationPatch is the wire representation of the measurements uploaded
+	// by the caller while the session is initializing.
+	initializationPatch struct {
+		Members map[string]memberPatch `json:"members"`
+	}
+
+	// memberPatch contains changes to the properties of a member.
+	memberPatch struct {
+		Properties memberPropertiesPatch `json:"properties"`
+	}
+
+	// memberPropertiesPatch contains changes to the properties of a member.
+	memberPropertiesPatch struct {
+		System memberMeasurementsPatch `json:"system"`
+	}
+
+	// memberMeasurementsPatch contains the measurements of a member. Unlike
+	// [MemberPropertiesSystem], it does not encode unrelated system properties
+	// such as the connection of the member.
+	memberMeasurementsPatch struct {
+		Measurements       map[string]Measurement       `json:"measurements,omitempty"`
+		ServerMeasurements map[string]ServerMeasurement `json:"serverMeasurements,omitempty"`
+	}
+)
@@ -0,0 +1,245 @@
+package mpsd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+	"github.com/google/uuid"
+)
+
+// Invites returns the invite handles addressed to the caller that have not yet expired.
+//
+// Each returned [InviteHandle] can be accepted by [Client.AcceptInvite] to join the
+// multiplayer session, or declined by [Client.DeclineInvite].
+func (c *Client) Invites(ctx context.Context, opts ...internal.RequestOption) ([]InviteHandle, error) {
+	var (
+		requestURL = endpoint.JoinPath("handles/query")
+		result     struct {
+			Invites []InviteHandle `json:"results"`
+		}
+	)
+	requestURL.RawQuery = "include=relatedInfo"
+	if err := internal.Do(ctx, c.client, http.MethodPost, requestURL.String(), inviteSearchRequest{
+		Type:        "invite",
+		InvitedXUID: c.userInfo.XUID,
+	}, &result, append(opts,
+		internal.RequestHeader("Content-Type", "application/json"),
+		internal.ContractVersion(contractVersion),
+	)); err != nil {
+		return nil, err
+	}
+	return result.Invites, nil
+}
+
+// AcceptInvite accepts the invite by joining the multiplayer session referenced by the
+// [InviteHandle]. It behaves like [Client.Join] using the ID of the handle.
+func (c *Client) AcceptInvite(ctx context.Context, handle InviteHandle, config JoinConfig, opts ...internal.RequestOption) (*Session, error) {
+	if !handle.Expiration.IsZero() && time.Now().After(handle.Expiration) {
+		return nil, fmt.Errorf("mpsd: invite handle %s has expired", handle.ID)
+	}
+	return c.Join(ctx, handle.ID, config, opts...)
+}
+
+// DeclineInvite declines the invite by deleting the [InviteHandle] from the directory.
+// The invite will no longer be included in the result of [Client.Invites].
+func (c *Client) DeclineInvite(ctx context.Context, handle InviteHandle, opts ...internal.RequestOption) error {
+	req, err := internal.NewRequest(ctx, http.MethodDelete, handle.URL().String(), nil, append(opts,
+		internal.ContractVersion(contractVersion),
+	))
+	if err != nil {
+		return fmt.Errorf("make request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	default:
+		return internal.UnexpectedStatusCode(resp)
+	}
+}
+
+// URL returns the URL locating to the resource for the invite handle.
+func (h InviteHandle) URL() *url.URL {
+	return endpoint.JoinPath(
+		"handles",
+		h.ID.String(),
+	)
+}
+
+// InviteHandler receives invites addressed to the caller as they arrive.
+// It can be registered on a Client via [Client.HandleInvites].
+type InviteHandler interface {
+	// HandleInvite is called when a new invite addressed to the caller is
+	// observed in the directory.
+	HandleInvite(handle InviteHandle)
+}
+
+// NopInviteHandler is a no-op implementation of [InviteHandler].
+type NopInviteHandler struct{}
+
+// HandleInvite implements [InviteHandler.HandleInvite].
+func (NopInviteHandler) HandleInvite(InviteHandle) {}
+
+// InviteConfig describes a configuration for receiving invites with [Client.HandleInvites].
+type InviteConfig struct {
+	// PollInterval is the interval at which the invite handles addressed to the caller
+	// are polled, in addition to the polls triggered over the RTA subscription. If zero,
+	// a default of 30 seconds is used.
+	PollInterval time.Duration
+}
+
+// HandleInvites registers h to receive invites addressed to the caller.
+//
+// The invite handles of the caller are queried in the background and new invites are
+// delivered to h, in the same way as [Client.PollInvites]. A query is triggered by the RTA
+// (Real-Time Activity) subscription used by the Client for multiplayer sessions whenever
+// a shoulder tap is received for a session not tracked by the Client, which may indicate
+// that the caller has been invited to the session, and whenever the subscription is
+// resynchronized. Triggers received while a query is in progress are coalesced into a
+// single query. As the directory does not guarantee a shoulder tap for each invite, the
+// invite handles are also polled at [InviteConfig.PollInterval]. The subscription is
+// created if it is not present.
+//
+// Invites already addressed to the caller at the time of registration are not delivered
+// to h, and may be retrieved using [Client.Invites]. Passing nil unregisters the handler
+// and stops polling, as does closing the Client.
+func (c *Client) HandleInvites(ctx context.Context, h InviteHandler, config InviteConfig) error {
+	if h == nil {
+		c.invitesMu.Lock()
+		c.stopInvitePolling()
+		c.inviteHandler, c.seenInvites = nil, nil
+		c.inviteGeneration++
+		c.invitesMu.Unlock()
+		return nil
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second * 30
+	}
+	if _, err := c.subscribe(ctx); err != nil {
+		return err
+	}
+	invites, err := c.Invites(ctx)
+	if err != nil {
+		return fmt.Errorf("mpsd: query invites: %w", err)
+	}
+
+	c.invitesMu.Lock()
+	defer c.invitesMu.Unlock()
+	c.stopInvitePolling()
+	c.inviteHandler = h
+	c.inviteGeneration++
+	c.seenInvites = make(map[uuid.UUID]struct{}, len(invites))
+	for _, invite := range invites {
+		c.seenInvites[invite.ID] = struct{}{}
+	}
+	pollCtx, cancel := context.WithCancel(context.Background())
+	c.stopInvites = cancel
+	go c.pollInvites(pollCtx, config.PollInterval)
+	return nil
+}
+
+// stopInvitePolling stops the background polling started by [Client.HandleInvites], if
+// any. invitesMu must be held.
+func (c *Client) stopInvitePolling() {
+	if c.stopInvites != nil {
+		c.stopInvites()
+		c.stopInvites = nil
+	}
+}
+
+// pollInvites polls for invites addressed to the caller at the interval and whenever
+// triggered by triggerInvitePoll, until ctx is done.
+func (c *Client) pollInvites(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-c.invitePollPending:
+		}
+
+		pollCtx, cancel := context.WithTimeout(ctx, time.Second*15)
+		err := c.PollInvites(pollCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			c.log.Error("error polling invites", slog.Any("error", err))
+		}
+	}
+}
+
+// triggerInvitePoll requests the background polling started by [Client.HandleInvites]
+// to query the invites addressed to the caller. Requests made while a query is pending
+// are coalesced into a single query.
+func (c *Client) triggerInvitePoll() {
+	select {
+	case c.invitePollPending <- struct{}{}:
+	default:
+	}
+}
+
+// PollInvites queries the invite handles addressed to the caller and delivers invites that
+// have not yet been seen to the InviteHandler registered via [Client.HandleInvites]. The new
+// invites are delivered sequentially in the order returned by the directory, before PollInvites
+// returns. It is a no-op if no InviteHandler has been registered.
+func (c *Client) PollInvites(ctx context.Context, opts ...internal.RequestOption) error {
+	c.pollInvitesMu.Lock()
+	defer c.pollInvitesMu.Unlock()
+
+	c.invitesMu.Lock()
+	registered, generation := c.inviteHandler != nil, c.inviteGeneration
+	c.invitesMu.Unlock()
+	if !registered {
+		return nil
+	}
+
+	invites, err := c.Invites(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("mpsd: query invites: %w", err)
+	}
+
+	c.invitesMu.Lock()
+	if c.inviteGeneration != generation {
+		// The handler has been replaced or unregistered while querying invites.
+		c.invitesMu.Unlock()
+		return nil
+	}
+	h := c.inviteHandler
+	seen := make(map[uuid.UUID]struct{}, len(invites))
+	var unseen []InviteHandle
+	for _, invite := range invites {
+		seen[invite.ID] = struct{}{}
+		if _, ok := c.seenInvites[invite.ID]; !ok {
+			unseen = append(unseen, invite)
+		}
+	}
+	// Replace the seen invites so that handles that have expired or have been
+	// deleted from the directory are no longer retained.
+	c.seenInvites = seen
+	c.invitesMu.Unlock()
+
+	for _, invite := range unseen {
+		h.HandleInvite(invite)
+	}
+	return nil
+}
+
+// inviteSearchRequest represents the on-wire format used for searching
+// invite handles addressed to a user in the directory.
+type inviteSearchRequest struct {
+	// Type indicates the type for the request.
+	// For inviteSearchRequest, this is always "invite".
+	Type string `json:"type"`
+	// InvitedXUID is the XUID of the user to whom the invites are addressed.
+	InvitedXUID string `json:"invitedXuid"`
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/df-mc/go-xsapi/v2/internal"
 	"github.com/google/uuid"
@@ -28,6 +27,12 @@ type JoinConfig struct {
 	//
 	// The format and semantics of this field are defined by the title.
 	CustomMemberProperties json.RawMessage
+
+	// Validate enables client-side validation before joining the session. If true, the
+	// handle is retrieved with the related info of the session, along with the session
+	// template of the session, and an error is returned without joining the session if
+	// it is closed, full, or uses a template that is incompatible with this package.
+	Validate bool
 }
 
 // Join joins a multiplayer session in the directory using the provided handle ID.
@@ -42,6 +47,11 @@ type JoinConfig struct {
 // A Session may be returned, which represents the joined multiplayer session.
 // Make sure to call [Session.Close] to leave the session when it is no longer needed.
 func (c *Client) Join(ctx context.Context, handleID uuid.UUID, config JoinConfig, opts ...internal.RequestOption) (*Session, error) {
+	if config.Validate {
+		if err := c.validateJoin(ctx, handleID, opts); err != nil {
+			return nil, err
+		}
+	}
 	connectionID, err := c.subscribe(ctx)
 	if err != nil {
 		return nil, err
@@ -49,26 +59,7 @@ func (c *Client) Join(ctx context.Context, handleID uuid.UUID, config JoinConfig
 
 	d := SessionDescription{
 		Members: map[string]*MemberDescription{
-			"me": {
-				Constants: &MemberConstants{
-					System: &MemberConstantsSystem{
-						Initialize: true,
-						XUID:       c.userInfo.XUID,
-					},
-					Custom: config.CustomMemberConstants,
-				},
-				Properties: &MemberProperties{
-					System: &MemberPropertiesSystem{
-						Active:     true,
-						Connection: connectionID,
-						Subscription: &MemberPropertiesSystemSubscription{
-							ID:          strings.ToUpper(uuid.NewString()),
-							ChangeTypes: []string{ChangeTypeEverything},
-						},
-					},
-					Custom: config.CustomMemberProperties,
-				},
-			},
+			"me": newMember(c.userInfo.XUID, connectionID, config.CustomMemberConstants, config.CustomMemberProperties),
 		},
 	}
 
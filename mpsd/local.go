@@ -0,0 +1,151 @@
+package mpsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+// ErrNotLocalMember is returned by [Session.RemoveLocalMember] and
+// [Session.SetLocalMemberCustomProperties] when the XUID does not identify
+// a member added via [Session.AddLocalMember].
+var ErrNotLocalMember = errors.New("mpsd: not a local member")
+
+// AddLocalMember adds the user authenticated by the Client as an additional member of the
+// multiplayer session. It is intended for split-screen or shared-device setups, where several
+// users signed in on the same device with their own token sources take part in one session.
+// The Client must be created for the additional user, typically sharing the RTA provider of
+// the Client that created the Session. The JoinConfig is applied to the initial contents of
+// the added member; [JoinConfig.Validate] is ignored.
+//
+// The added member is tracked by the Session: its custom properties can be updated with
+// [Session.SetLocalMemberCustomProperties], it can be removed with [Session.RemoveLocalMember],
+// and it is removed from the session before the caller in [Session.Leave]. Notifications and
+// synchronization of the Session are still performed on behalf of the user who created the
+// Session, and no activity handle is published for the added member.
+//
+// The connection ID of the added member is not updated when the RTA subscription of its
+// Client reconnects. In that case, the member should be removed and added again.
+func (s *Session) AddLocalMember(ctx context.Context, local *Client, config JoinConfig, opts ...internal.RequestOption) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	if local == s.client || local.userInfo.XUID == s.client.userInfo.XUID {
+		return errors.New("mpsd: local member is the owner of the session")
+	}
+	s.localsMu.Lock()
+	deleted, err := s.addLocalMemberLocked(ctx, local, config, opts)
+	s.localsMu.Unlock()
+	if deleted {
+		s.markDeleted()
+		return fmt.Errorf("mpsd: session %s was deleted while adding local member", s.ref.URL())
+	}
+	return err
+}
+
+// addLocalMemberLocked adds the user authenticated by the Client as a member of the
+// multiplayer session. It reports whether MPSD deleted the session as a result. The
+// caller is responsible for marking s as deleted. s.localsMu must be held.
+func (s *Session) addLocalMemberLocked(ctx context.Context, local *Client, config JoinConfig, opts []internal.RequestOption) (deleted bool, err error) {
+	xuid := local.userInfo.XUID
+	if _, ok := s.locals[xuid]; ok {
+		return false, fmt.Errorf("mpsd: %s is already a local member", xuid)
+	}
+	connectionID, err := local.subscribe(ctx)
+	if err != nil {
+		return false, err
+	}
+	deleted, err = s.updateAs(ctx, local.client, SessionDescription{
+		Members: map[string]*MemberDescription{
+			"me": newMember(xuid, connectionID, config.CustomMemberConstants, config.CustomMemberProperties),
+		},
+	}, "*", opts)
+	if err != nil || deleted {
+		return deleted, err
+	}
+	if s.locals == nil {
+		s.locals = make(map[string]*Client)
+	}
+	s.locals[xuid] = local
+	return false, nil
+}
+
+// RemoveLocalMember removes the member identified by the XUID, previously added via
+// [Session.AddLocalMember], from the multiplayer session. The caller remains a member.
+// An error wrapping [ErrNotLocalMember] is returned if the XUID is not a local member.
+func (s *Session) RemoveLocalMember(ctx context.Context, xuid string, opts ...internal.RequestOption) error {
+	s.localsMu.Lock()
+	deleted, err := s.removeLocalMemberLocked(ctx, xuid, opts)
+	s.localsMu.Unlock()
+	if deleted {
+		s.markDeleted()
+	}
+	return err
+}
+
+// removeLocalMemberLocked removes the local member identified by the XUID from the
+// multiplayer session. It reports whether MPSD deleted the session as a result. The
+// caller is responsible for marking s as deleted. s.localsMu must be held.
+func (s *Session) removeLocalMemberLocked(ctx context.Context, xuid string, opts []internal.RequestOption) (deleted bool, err error) {
+	local, ok := s.locals[xuid]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrNotLocalMember, xuid)
+	}
+	deleted, err = s.updateAs(ctx, local.client, SessionDescription{
+		Members: map[string]*MemberDescription{"me": nil},
+	}, "*", opts)
+	if err != nil {
+		return false, err
+	}
+	delete(s.locals, xuid)
+	return deleted, nil
+}
+
+// removeLocalMembers removes every member added via [Session.AddLocalMember] from the
+// multiplayer session. It stops at the first member that could not be removed, or once
+// MPSD reports that the session has been deleted.
+func (s *Session) removeLocalMembers(ctx context.Context) (deleted bool, err error) {
+	s.localsMu.Lock()
+	defer s.localsMu.Unlock()
+	for xuid := range s.locals {
+		deleted, err = s.removeLocalMemberLocked(ctx, xuid, nil)
+		if err != nil {
+			return false, fmt.Errorf("remove local member %s: %w", xuid, err)
+		}
+		if deleted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetLocalMemberCustomProperties updates the custom properties of the member identified by
+// the XUID, previously added via [Session.AddLocalMember]. The changes are committed on behalf
+// of the member. An error wrapping [ErrNotLocalMember] is returned if the XUID is not a local member.
+func (s *Session) SetLocalMemberCustomProperties(ctx context.Context, xuid string, custom json.RawMessage, opts ...internal.RequestOption) error {
+	s.localsMu.Lock()
+	local, ok := s.locals[xuid]
+	s.localsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotLocalMember, xuid)
+	}
+	deleted, err := s.updateAs(ctx, local.client, SessionDescription{
+		Members: map[string]*MemberDescription{
+			"me": {
+				Properties: &MemberProperties{
+					Custom: custom,
+				},
+			},
+		},
+	}, "*", opts)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		s.markDeleted()
+	}
+	return nil
+}
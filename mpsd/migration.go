@@ -0,0 +1,160 @@
+package mpsd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// HostMigrationHandler receives events about the host of a multiplayer session.
+// It can be registered on a Session via [Session.HandleHostMigration].
+type HostMigrationHandler interface {
+	// HandleHostMigrated is called when the host of the session has changed
+	// to another member, including when the caller has claimed the host.
+	HandleHostMigrated(session *Session, migration HostMigration)
+}
+
+// NopHostMigrationHandler is a no-op implementation of [HostMigrationHandler].
+type NopHostMigrationHandler struct{}
+
+// HandleHostMigrated implements [HostMigrationHandler.HandleHostMigrated].
+func (NopHostMigrationHandler) HandleHostMigrated(*Session, HostMigration) {}
+
+// HostMigration describes a change of the host of a multiplayer session.
+type HostMigration struct {
+	// Previous is the device token of the previous host. It may be empty if
+	// the session did not have a host.
+	Previous string
+	// Current is the device token of the new host.
+	Current string
+	// Label is the label of the member whose device token matches Current.
+	// It is empty if no such member is present in the cached session state.
+	Label string
+	// Self reports whether the caller is the new host.
+	Self bool
+}
+
+// HandleHostMigration opts in to host arbitration for the multiplayer session and registers
+// h to receive [HostMigration] events.
+//
+// Whenever the session is synchronized, the host of the session, as identified by
+// [SessionPropertiesSystem.Host], is compared against the device tokens of the active members.
+// If the session has no host, or the host is no longer an active member, the active member with
+// the lowest numeric label is elected as the candidate. Every client with arbitration enabled
+// elects the same candidate, and only the candidate attempts to claim the host. The claim is
+// committed conditionally on the ETag of the cached session, so concurrent claims cannot both
+// succeed; a claim that loses is abandoned and the arbitration is repeated on the next change.
+//
+// Passing nil disables host arbitration.
+func (s *Session) HandleHostMigration(h HostMigrationHandler) {
+	s.hostMu.Lock()
+	if h == nil {
+		s.arbiter = nil
+	} else {
+		s.arbiter = &hostArbiter{handler: h, host: s.host()}
+	}
+	s.hostMu.Unlock()
+
+	s.arbitrateHost()
+}
+
+// hostArbiter holds the state of host arbitration for a Session.
+type hostArbiter struct {
+	handler HostMigrationHandler
+	// host is the device token of the host at the time of the last event.
+	host string
+	// claiming reports whether a claim of the host is in progress.
+	claiming bool
+}
+
+// host returns the device token of the host in the cached session state.
+func (s *Session) host() string {
+	properties := s.Properties()
+	if properties.System == nil {
+		return ""
+	}
+	return properties.System.Host
+}
+
+// arbitrateHost reports a [HostMigration] if the host of the cached session state has changed,
+// and claims the host if the caller is the candidate for a session without an active host.
+// It is a no-op if [Session.HandleHostMigration] has not been called.
+func (s *Session) arbitrateHost() {
+	s.hostMu.Lock()
+	defer s.hostMu.Unlock()
+	arbiter := s.arbiter
+	if arbiter == nil {
+		return
+	}
+
+	host := s.host()
+	selfLabel, self, _ := s.self()
+	var hostLabel, candidate string
+	lowest := uint64(math.MaxUint64)
+	for label, member := range s.Members() {
+		if member.Status() != MemberStatusActive || member.DeviceToken == "" {
+			continue
+		}
+		if member.DeviceToken == host {
+			hostLabel = label
+		}
+		if n, err := strconv.ParseUint(label, 10, 64); err == nil && n < lowest {
+			candidate, lowest = label, n
+		}
+	}
+
+	if host != arbiter.host && host != "" {
+		migration := HostMigration{
+			Previous: arbiter.host,
+			Current:  host,
+			Label:    hostLabel,
+			Self:     self.DeviceToken != "" && self.DeviceToken == host,
+		}
+		arbiter.host = host
+		go arbiter.handler.HandleHostMigrated(s, migration)
+	}
+
+	if hostLabel != "" || candidate == "" || candidate != selfLabel || arbiter.claiming || s.readOnly {
+		return
+	}
+	s.cacheMu.RLock()
+	etag := s.etag
+	s.cacheMu.RUnlock()
+	if etag == "" {
+		return
+	}
+	arbiter.claiming = true
+	go s.claimHost(arbiter, self.DeviceToken, etag)
+}
+
+// claimHost commits the device token as the host of the multiplayer session if the
+// remote session still matches the ETag.
+func (s *Session) claimHost(arbiter *hostArbiter, deviceToken, etag string) {
+	ctx, cancel := context.WithTimeout(s.Context(), time.Second*15)
+	defer cancel()
+	deleted, err := s.updateMatch(ctx, sessionPatch{
+		Properties: &sessionPropertiesPatch{
+			System: &sessionPropertiesSystemPatch{Host: &deviceToken},
+		},
+	}, etag, nil)
+
+	s.hostMu.Lock()
+	arbiter.claiming = false
+	s.hostMu.Unlock()
+
+	switch {
+	case deleted:
+		s.markDeleted()
+	case errors.Is(err, ErrConflict):
+		s.log.Debug("lost host claim to a concurrent change")
+	case err != nil:
+		if !errors.Is(err, context.Canceled) {
+			s.log.Error("error claiming host", slog.Any("error", err))
+		}
+	default:
+		s.arbitrateHost()
+	}
+}
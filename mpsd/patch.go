@@ -0,0 +1,210 @@
+package mpsd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+// patchAttempts is the maximum number of attempts made by [Session.PatchCustomProperties]
+// and [Session.PatchMemberCustomProperties] to commit a patch before giving up when the
+// multiplayer session keeps being modified concurrently.
+const patchAttempts = 5
+
+// PatchCustomProperties applies the JSON merge patch, as described in RFC 7396, to the
+// custom properties of the multiplayer session. Unlike [Session.SetCustomProperties], only
+// the fields present in the patch are changed, and fields set to null in the patch are
+// removed, so that several parts of the title can own different fields of the properties.
+//
+// The patch is applied to the cached custom properties, and the result is committed only if
+// the remote session still matches the cached ETag. If the session has been modified since,
+// the Session is synchronized and the patch is applied again. An error matching [ErrConflict]
+// is returned if the session keeps being modified concurrently, and [ErrSessionDeleted] if the
+// session has been deleted instead.
+func (s *Session) PatchCustomProperties(ctx context.Context, patch json.RawMessage, opts ...internal.RequestOption) error {
+	return s.patchCustom(ctx, patch, func(d SessionDescription) json.RawMessage {
+		if d.Properties == nil {
+			return nil
+		}
+		return d.Properties.Custom
+	}, func(custom json.RawMessage) any {
+		return SessionDescription{
+			Properties: &SessionProperties{
+				Custom: custom,
+			},
+		}
+	}, opts)
+}
+
+// PatchMemberCustomProperties applies the JSON merge patch, as described in RFC 7396, to the
+// custom properties of the specified member. The special label "me" refers to the current
+// authenticated caller. Only the owning member may modify their own properties.
+//
+// The patch is committed in the same way as [Session.PatchCustomProperties].
+func (s *Session) PatchMemberCustomProperties(ctx context.Context, label string, patch json.RawMessage, opts ...internal.RequestOption) error {
+	return s.patchCustom(ctx, patch, func(d SessionDescription) json.RawMessage {
+		member := d.Members[label]
+		if label == "me" {
+			for _, m := range d.Members {
+				if m != nil && m.Constants != nil && m.Constants.System != nil && m.Constants.System.XUID == s.client.userInfo.XUID {
+					member = m
+					break
+				}
+			}
+		}
+		if member == nil || member.Properties == nil {
+			return nil
+		}
+		return member.Properties.Custom
+	}, func(custom json.RawMessage) any {
+		return SessionDescription{
+			Members: map[string]*MemberDescription{
+				label: {
+					Properties: &MemberProperties{
+						Custom: custom,
+					},
+				},
+			},
+		}
+	}, opts)
+}
+
+// SetCustomProperty sets the field located by the path in the custom properties of the
+// multiplayer session to the JSON encoding of the value, leaving other fields unchanged.
+// The path is a dot-separated list of field names, such as "worldInfo.playerCount". Objects
+// along the path are created as needed. A nil value removes the field.
+//
+// It is a shorthand for [Session.PatchCustomProperties] with a patch built from the path.
+func (s *Session) SetCustomProperty(ctx context.Context, path string, value any, opts ...internal.RequestOption) error {
+	patch, err := pathPatch(path, value)
+	if err != nil {
+		return err
+	}
+	return s.PatchCustomProperties(ctx, patch, opts...)
+}
+
+// SetMemberCustomProperty sets the field located by the path in the custom properties of the
+// specified member, in the same way as [Session.SetCustomProperty]. The special label "me"
+// refers to the current authenticated caller.
+func (s *Session) SetMemberCustomProperty(ctx context.Context, label, path string, value any, opts ...internal.RequestOption) error {
+	patch, err := pathPatch(path, value)
+	if err != nil {
+		return err
+	}
+	return s.PatchMemberCustomProperties(ctx, label, patch, opts...)
+}
+
+// patchCustom applies the patch to the custom properties returned by current from the
+// cached session state, and commits the result using the changes returned by changes.
+// The changes are only committed if the remote session matches the cached ETag, and
+// the Session is synchronized before retrying on conflicts.
+func (s *Session) patchCustom(ctx context.Context, patch json.RawMessage, current func(SessionDescription) json.RawMessage, changes func(json.RawMessage) any, opts []internal.RequestOption) error {
+	var conflict error
+	for range patchAttempts {
+		s.cacheMu.RLock()
+		custom, etag := current(s.cache), s.etag
+		s.cacheMu.RUnlock()
+		if etag == "" {
+			// The cached state cannot be matched against the remote
+			// session without an ETag, so it needs to be refreshed.
+			if err := s.Sync(ctx); err != nil {
+				return err
+			}
+			s.cacheMu.RLock()
+			custom, etag = current(s.cache), s.etag
+			s.cacheMu.RUnlock()
+			if etag == "" {
+				return errors.New("mpsd: missing ETag of session")
+			}
+		}
+
+		merged, err := mergePatch(custom, patch)
+		if err != nil {
+			return fmt.Errorf("mpsd: apply merge patch: %w", err)
+		}
+		deleted, err := s.updateMatch(ctx, changes(merged), etag, opts)
+		if deleted {
+			// The session was deleted by the directory, so the patch has not been applied.
+			s.markDeleted()
+			return ErrSessionDeleted
+		}
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+		conflict = err
+		if err := s.Sync(ctx); err != nil {
+			return err
+		}
+	}
+	return conflict
+}
+
+// mergePatch applies the JSON merge patch to the target document as described in RFC 7396
+// and returns the resulting document. An empty target is treated as an absent document.
+func mergePatch(target, patch json.RawMessage) (json.RawMessage, error) {
+	var t, p any
+	if len(target) > 0 {
+		if err := decodeNumbers(target, &t); err != nil {
+			return nil, fmt.Errorf("decode target: %w", err)
+		}
+	}
+	if err := decodeNumbers(patch, &p); err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+	return json.Marshal(mergeValue(t, p))
+}
+
+// mergeValue merges the decoded patch into the decoded target. The target may be modified.
+func mergeValue(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = make(map[string]any, len(fields))
+	}
+	for name, value := range fields {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = mergeValue(result[name], value)
+	}
+	return result
+}
+
+// decodeNumbers decodes the JSON data into v, preserving numbers as [json.Number]
+// so that they are encoded again without loss of precision.
+func decodeNumbers(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// pathPatch returns a JSON merge patch that sets the field located by the dot-separated
+// path to the JSON encoding of the value.
+func pathPatch(path string, value any) (json.RawMessage, error) {
+	names := strings.Split(path, ".")
+	if slices.Contains(names, "") {
+		return nil, fmt.Errorf("mpsd: invalid property path %q", path)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("mpsd: encode property %q: %w", path, err)
+	}
+	patch := json.RawMessage(data)
+	for i := len(names) - 1; i >= 0; i-- {
+		patch, err = json.Marshal(map[string]json.RawMessage{names[i]: patch})
+		if err != nil {
+			return nil, fmt.Errorf("mpsd: encode property %q: %w", path, err)
+		}
+	}
+	return patch, nil
+}
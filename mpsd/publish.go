@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/df-mc/go-xsapi/v2/internal"
 	"github.com/google/uuid"
@@ -29,6 +30,10 @@ type PublishConfig struct {
 	//
 	// The format and semantics of this field are defined by the title.
 	CustomConstants json.RawMessage
+	// SystemConstants holds immutable system constants to be associated with the multiplayer
+	// session when publishing, such as the capabilities or the maximum number of members.
+	// Constants that are already defined by the session template cannot be overridden.
+	SystemConstants *SessionConstantsSystem
 
 	// CustomMemberProperties holds mutable properties associated with the host.
 	// Unlike [JoinConfig.CustomMemberConstants], these can be updated at any time
@@ -45,6 +50,12 @@ type PublishConfig struct {
 	// JoinRestriction and ReadRestriction specify who may join or read an open session.
 	// If JoinRestriction or ReadRestriction are empty, it will default to [SessionRestrictionFollowed].
 	JoinRestriction, ReadRestriction string
+
+	// Validate enables client-side validation of the configuration before the session is
+	// published. If true, the session template referenced by [SessionReference.TemplateName]
+	// is retrieved using [Client.SessionTemplate], and an error is returned without publishing
+	// the session if the configuration is invalid or conflicts with the template.
+	Validate bool
 }
 
 // Publish publishes a new multiplayer session in the directory using the
@@ -63,6 +74,15 @@ func (c *Client) Publish(ctx context.Context, ref SessionReference, config Publi
 	if config.ReadRestriction == "" {
 		config.ReadRestriction = SessionRestrictionFollowed
 	}
+	if config.Validate {
+		template, err := c.SessionTemplate(ctx, ref.ServiceConfigID, ref.TemplateName, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("mpsd: retrieve session template %q: %w", ref.TemplateName, err)
+		}
+		if err := validatePublish(template, config); err != nil {
+			return nil, err
+		}
+	}
 	connectionID, err := c.subscribe(ctx)
 	if err != nil {
 		return nil, err
@@ -77,30 +97,12 @@ func (c *Client) Publish(ctx context.Context, ref SessionReference, config Publi
 			Custom: config.CustomProperties,
 		},
 		Members: map[string]*MemberDescription{
-			"me": {
-				Constants: &MemberConstants{
-					System: &MemberConstantsSystem{
-						Initialize: true,
-						XUID:       c.userInfo.XUID,
-					},
-					Custom: config.CustomMemberConstants,
-				},
-				Properties: &MemberProperties{
-					System: &MemberPropertiesSystem{
-						Active:     true,
-						Connection: connectionID,
-						Subscription: &MemberPropertiesSystemSubscription{
-							ID:          strings.ToUpper(uuid.NewString()),
-							ChangeTypes: []string{ChangeTypeEverything},
-						},
-					},
-					Custom: config.CustomMemberProperties,
-				},
-			},
+			"me": newMember(c.userInfo.XUID, connectionID, config.CustomMemberConstants, config.CustomMemberProperties),
 		},
 	}
-	if config.CustomConstants != nil {
+	if config.CustomConstants != nil || config.SystemConstants != nil {
 		d.Constants = &SessionConstants{
+			System: config.SystemConstants,
 			Custom: config.CustomConstants,
 		}
 	}
@@ -130,6 +132,31 @@ func (c *Client) Publish(ctx context.Context, ref SessionReference, config Publi
 	}
 }
 
+// newMember returns the description of a new active member for the user identified by
+// the XUID, subscribed to every change of the session using the RTA connection ID.
+func newMember(xuid string, connectionID uuid.UUID, constants, properties json.RawMessage) *MemberDescription {
+	return &MemberDescription{
+		Constants: &MemberConstants{
+			System: &MemberConstantsSystem{
+				Initialize: true,
+				XUID:       xuid,
+			},
+			Custom: constants,
+		},
+		Properties: &MemberProperties{
+			System: &MemberPropertiesSystem{
+				Active:     true,
+				Connection: connectionID,
+				Subscription: &MemberPropertiesSystemSubscription{
+					ID:          strings.ToUpper(uuid.NewString()),
+					ChangeTypes: []string{ChangeTypeEverything},
+				},
+			},
+			Custom: properties,
+		},
+	}
+}
+
 // createSession creates a multiplayer session on the directory using the URL.
 // The URL may be a session reference or the handle referencing the session to join.
 // When joining an existing multiplayer session, the session reference may be
@@ -142,14 +169,25 @@ func (c *Client) createSession(ctx context.Context, ref SessionReference, resp *
 	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
 		return nil, fmt.Errorf("decode initial contents: %w", err)
 	}
+	s := c.newSession(ref, d, resp.Header.Get("ETag"))
+	if err := c.bindSession(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newSession returns a Session for the multiplayer session identified by ref
+// using d as the initial cached state.
+func (c *Client) newSession(ref SessionReference, d SessionDescription, etag string) *Session {
 	s := &Session{
 		client: c,
 
-		h:      NopHandler{}, // fast-path without locking
-		cache:  d,
-		etag:   resp.Header.Get("ETag"),
-		ref:    ref,
-		closed: make(chan struct{}),
+		h:        NopHandler{}, // fast-path without locking
+		cache:    d,
+		reported: memberStates(d),
+		etag:     etag,
+		ref:      ref,
+		closed:   make(chan struct{}),
 	}
 	s.log = c.log.With(
 		slog.Group("session",
@@ -158,13 +196,20 @@ func (c *Client) createSession(ctx context.Context, ref SessionReference, resp *
 			slog.String("name", s.ref.Name),
 		),
 	)
+	return s
+}
 
+// bindSession publishes an activity handle for s, registers s to the Client so it
+// receives notifications from the RTA subscription, and updates the connection ID
+// of the caller in s to the current RTA connection ID. If any of these steps fails,
+// s is closed and an error is returned.
+func (c *Client) bindSession(ctx context.Context, s *Session) error {
 	if err := s.writeActivity(ctx); err != nil {
 		err = fmt.Errorf("write activity handle: %w", err)
 		if err2 := s.Close(); err2 != nil {
 			err = errors.Join(err, fmt.Errorf("close session: %w", err2))
 		}
-		return nil, err
+		return err
 	}
 
 	// Bind the session before reconciliation so concurrent RTA reconnect
@@ -175,16 +220,14 @@ func (c *Client) createSession(ctx context.Context, ref SessionReference, resp *
 
 	if err := c.reconcileSessionConnection(ctx, s); err != nil {
 		err = fmt.Errorf("update session %s connection ID: %w", s.Reference().URL(), err)
-		if err2 := s.Close(); err2 != nil {
+		closeCtx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+		defer cancel()
+		if err2 := s.abandon(closeCtx, fmt.Errorf("%w: %w", ErrReconcileFailed, err)); err2 != nil {
 			err = errors.Join(err, fmt.Errorf("close session: %w", err2))
-			s.closeMu.Lock()
-			s.closeLocked()
-			s.closeMu.Unlock()
 		}
-		return nil, err
+		return err
 	}
-
-	return s, nil
+	return nil
 }
 
 // reconcileSessionConnection updates s to use the Client's current RTA
@@ -248,6 +291,10 @@ func sessionConnectionCurrent(s *Session, connectionID uuid.UUID) bool {
 // longer receive notifications from the RTA subscription.
 func (c *Client) handleSessionClose(s *Session) {
 	c.sessionsMu.Lock()
-	delete(c.sessions, s.ref.URL().String())
+	// A read-only Session returned by Watch may share the reference of
+	// a tracked Session, so only remove the entry if it refers to s.
+	if key := s.ref.URL().String(); c.sessions[key] == s {
+		delete(c.sessions, key)
+	}
 	c.sessionsMu.Unlock()
 }
@@ -0,0 +1,94 @@
+package mpsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+// Reserve reserves a slot in the multiplayer session for each user identified by the XUIDs.
+//
+// Reservations allow a host to pre-allocate slots for users, such as party members,
+// before they connect. A reserved member is listed in [Session.Members] with the
+// status [MemberStatusReserved] until the reserved user joins the session, or until
+// [SessionConstantsSystem.ReservedRemovalTimeout] elapses.
+//
+// The ReserveConfig is applied to each reserved member. An error wrapping [ErrTooManyMembers]
+// is returned without making any request if the reservations would exceed
+// [SessionConstantsSystem.MaxMembersCount].
+func (s *Session) Reserve(ctx context.Context, xuids []string, config ReserveConfig, opts ...internal.RequestOption) error {
+	if len(xuids) == 0 {
+		return errors.New("mpsd: no XUIDs to reserve")
+	}
+	if limit := s.maxMembersCount(); limit != 0 {
+		var count int
+		for range s.Members() {
+			count++
+		}
+		if count+len(xuids) > int(limit) {
+			return fmt.Errorf("%w: reserving %d members in session with %d of %d members", ErrTooManyMembers, len(xuids), count, limit)
+		}
+	}
+
+	members := make(map[string]*MemberDescription, len(xuids))
+	for i, xuid := range xuids {
+		// Labels prefixed with 'reserve_' are replaced with member IDs assigned
+		// by the directory.
+		members["reserve_"+strconv.Itoa(i)] = &MemberDescription{
+			Constants: &MemberConstants{
+				System: &MemberConstantsSystem{
+					XUID:       xuid,
+					Initialize: config.Initialize,
+				},
+				Custom: config.CustomConstants,
+			},
+		}
+	}
+	return s.commit(ctx, sessionPatch{Members: members}, opts)
+}
+
+// ReserveConfig describes the members reserved with [Session.Reserve].
+type ReserveConfig struct {
+	// CustomConstants holds the immutable custom constants of each reserved member.
+	// It may be nil.
+	CustomConstants json.RawMessage
+
+	// Initialize requests the reserved members to take part in the QoS initialization
+	// of the session once they join, as described in [MemberConstantsSystem.Initialize].
+	// It should only be set if the session template requires initialization.
+	Initialize bool
+}
+
+// CancelReservation removes the pending reservation for the user identified by the XUID.
+// An error wrapping [ErrMemberNotFound] is returned if no pending reservation exists for
+// the user in the cached session state.
+func (s *Session) CancelReservation(ctx context.Context, xuid string, opts ...internal.RequestOption) error {
+	for label, member := range s.Reservations() {
+		if member.Constants != nil && member.Constants.System != nil && member.Constants.System.XUID == xuid {
+			return s.RemoveMember(ctx, label, opts...)
+		}
+	}
+	return fmt.Errorf("%w: no reservation for xuid %s", ErrMemberNotFound, xuid)
+}
+
+// Reservations returns an iterator that yields members with pending reservations from
+// the cached session state. Like [Session.Members], the iterator operates over a snapshot
+// taken at the time of the call.
+func (s *Session) Reservations() iter.Seq2[string, MemberDescription] {
+	members := s.Members()
+	return func(yield func(string, MemberDescription) bool) {
+		for label, member := range members {
+			if member.Status() != MemberStatusReserved {
+				continue
+			}
+			if !yield(label, member) {
+				return
+			}
+		}
+	}
+}
@@ -3,12 +3,14 @@ package mpsd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -48,6 +50,10 @@ type Session struct {
 	// ref contains a reference to the multiplayer session.
 	ref SessionReference
 
+	// readOnly reports whether the Session was returned by [Client.Watch]. Mutations
+	// on a read-only Session fail with [ErrReadOnly].
+	readOnly bool
+
 	// etag holds the most recently observed E-Tag for the session resource.
 	// When reading or accessing etag, cacheMu must be held for concurrent safety.
 	etag string
@@ -65,6 +71,35 @@ type Session struct {
 	// hMu guards h from concurrent read/write access.
 	hMu sync.RWMutex
 
+	// reported holds the state of each member at the time of the last notification
+	// dispatched to h. It is used to compute [MemberChange] events.
+	reported map[string]memberState
+	// reportMu serializes computing member changes so that each change is
+	// reported exactly once.
+	reportMu sync.Mutex
+
+	// initialization is the initializer registered via [Session.Initialize], or nil if the
+	// caller has not opted in to taking part in the initialization of the session.
+	initialization *initializer
+	// initMu guards initialization from concurrent access.
+	initMu sync.Mutex
+
+	// arbiter is the host arbiter registered via [Session.HandleHostMigration], or
+	// nil if host arbitration is disabled.
+	arbiter *hostArbiter
+	// hostMu guards arbiter from concurrent access.
+	hostMu sync.Mutex
+
+	// locals holds the Clients of the members added via [Session.AddLocalMember],
+	// keyed by the XUID of each member.
+	locals map[string]*Client
+	// localsMu guards locals from concurrent access.
+	localsMu sync.Mutex
+
+	// taps tracks the shoulder taps received for the session over RTA so that
+	// redundant synchronizations can be skipped.
+	taps tapState
+
 	// closed is a channel that is closed when the Session is no longer usable.
 	//
 	// Goroutines may select on this channel to be notified when the session has been closed.
@@ -73,12 +108,22 @@ type Session struct {
 	// closeMu serializes remote close attempts so that a failed close can be retried
 	// without racing a successful one.
 	closeMu sync.Mutex
+
+	// ctx is the context returned by [Session.Context]. It is created on first use
+	// and is canceled with cause when the Session is closed.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	// cause is the reason why the Session has been closed.
+	cause error
+	// ctxMu guards ctx, cancel and cause, and orders them against closing s.closed.
+	ctxMu sync.Mutex
 }
 
 // Close closes the multiplayer session using a context with 15 seconds timeout.
+// It is equivalent to [Session.Leave].
 //
-// If the caller is the host of the multiplayer session, the session itself is closed.
-// If the caller is a non-host participant, this call ensures the caller to leave the session.
+// If the caller is the last member of the multiplayer session, the session itself is deleted.
+// Otherwise, this call ensures the caller to leave the session.
 //
 // Once CloseContext succeeds, the multiplayer session will no longer receive notifications
 // about changes in the session even though if the session still exist after leaving.
@@ -91,14 +136,31 @@ func (s *Session) Close() error {
 }
 
 // CloseContext closes the multiplayer session using the context.
+// It is equivalent to [Session.Leave].
 //
-// If the caller is the host of the multiplayer session, the session itself is closed.
-// If the caller is a non-host participant, this call ensures the caller to leave the session.
+// If the caller is the last member of the multiplayer session, the session itself is deleted.
+// Otherwise, this call ensures the caller to leave the session.
 //
 // Once CloseContext succeeds, the multiplayer session will no longer receive notifications
 // about changes in the session even though if the session still exist after leaving.
 // If the remote leave or close request fails, the Session remains usable and CloseContext may be retried.
 func (s *Session) CloseContext(ctx context.Context) error {
+	return s.Leave(ctx)
+}
+
+// Leave removes the caller from the multiplayer session, leaving the session in the
+// directory for other members. If the caller is the last member, the directory deletes
+// the session. Once Leave succeeds, the cause of [Session.Context] is [ErrSessionLeft],
+// or [ErrSessionDeleted] if the session has been deleted as a result.
+//
+// If the remote leave request fails, the Session remains usable and Leave may be retried.
+func (s *Session) Leave(ctx context.Context) error {
+	return s.leave(ctx, ErrSessionLeft)
+}
+
+// leave removes the caller from the multiplayer session and closes the Session
+// with the cause.
+func (s *Session) leave(ctx context.Context, cause error) error {
 	s.closeMu.Lock()
 	defer s.closeMu.Unlock()
 
@@ -107,6 +169,30 @@ func (s *Session) CloseContext(ctx context.Context) error {
 		return nil
 	default:
 	}
+	_, err := s.leaveLocked(ctx, cause, nil)
+	return err
+}
+
+// leaveLocked is leave with s.closeMu already held by the caller and s not yet closed.
+// It reports whether the directory has deleted the session as a result.
+func (s *Session) leaveLocked(ctx context.Context, cause error, opts []internal.RequestOption) (bool, error) {
+	if s.readOnly {
+		// The caller is not a member of a read-only session, so it
+		// is only closed locally.
+		s.closeLocked(cause)
+		return false, nil
+	}
+
+	// Remove local members first, so the session is not left with
+	// members that can no longer be tracked by the caller.
+	deleted, err := s.removeLocalMembers(ctx)
+	if err != nil {
+		return false, err
+	}
+	if deleted {
+		s.markDeletedLocked()
+		return true, nil
+	}
 
 	d := SessionDescription{
 		Members: map[string]*MemberDescription{
@@ -114,15 +200,87 @@ func (s *Session) CloseContext(ctx context.Context) error {
 			"me": nil,
 		},
 	}
-	deleted, err := s.update(ctx, d, nil)
+	deleted, err = s.update(ctx, d, opts)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if deleted {
 		s.markDeletedLocked()
+		return true, nil
+	}
+	s.closeLocked(cause)
+	return false, nil
+}
+
+// abandon leaves the multiplayer session and closes the Session with the cause. Unlike
+// [Session.Leave], the Session is closed locally even if the remote leave request fails.
+func (s *Session) abandon(ctx context.Context, cause error) error {
+	err := s.leave(ctx, cause)
+	if err != nil {
+		s.closeMu.Lock()
+		s.closeLocked(cause)
+		s.closeMu.Unlock()
+	}
+	return err
+}
+
+// Delete ends the multiplayer session for every member. It is typically called by
+// the host of the session.
+//
+// The session is closed to new members, and every member in the cached session state,
+// including the caller, is removed so that the directory deletes the session. Once
+// Delete succeeds, the cause of [Session.Context] is [ErrSessionDeleted].
+//
+// Only the caller is guaranteed to be removed, as members may join before the request is
+// committed. If the directory rejects the request with 403 Forbidden, such as when the
+// caller is not permitted to remove other members, the caller leaves the session as with
+// [Session.Leave] instead. If the session remains with other members, the Session is closed
+// with [ErrSessionLeft] as the cause of [Session.Context] and an error wrapping
+// [ErrSessionNotDeleted] is returned.
+//
+// If the request fails, the Session remains usable and Delete may be retried.
+func (s *Session) Delete(ctx context.Context, opts ...internal.RequestOption) error {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+
+	select {
+	case <-s.closed:
+		return nil
+	default:
+	}
+
+	closed := true
+	patch := sessionPatch{
+		Properties: &sessionPropertiesPatch{
+			System: &sessionPropertiesSystemPatch{Closed: &closed},
+		},
+		Members: map[string]*MemberDescription{"me": nil},
+	}
+	for label := range s.Members() {
+		patch.Members[label] = nil
+	}
+	deleted, err := s.update(ctx, patch, opts)
+	if responseErr := (*ResponseError)(nil); errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusForbidden {
+		// The whole request has been rejected, so the caller has not been removed either.
+		deleted, err = s.leaveLocked(ctx, ErrSessionLeft, opts)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return fmt.Errorf("%w: %s", ErrSessionNotDeleted, s.ref.URL())
+		}
 		return nil
 	}
-	s.closeLocked()
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		// The caller has been removed, but the directory has kept the session
+		// for the members that remain in it.
+		s.closeLocked(ErrSessionLeft)
+		return fmt.Errorf("%w: %s", ErrSessionNotDeleted, s.ref.URL())
+	}
+	s.markDeletedLocked()
 	return nil
 }
 
@@ -146,8 +304,98 @@ func (s *Session) handler() Handler {
 	return s.h
 }
 
+// notify dispatches events describing the cached session state to the registered
+// [Handler]. It is called after the session has been synchronized with the remote
+// state through RTA. If the caller is no longer a member of the session, the Session
+// is closed with [ErrMemberRemoved].
+func (s *Session) notify() {
+	h := s.handler()
+	h.HandleSessionChange(s)
+	memberHandler, _ := h.(MemberHandler)
+	var removed bool
+	for _, change := range s.memberChanges() {
+		if memberHandler != nil {
+			memberHandler.HandleMemberChange(s, change)
+		}
+		if change.Current == MemberStatusNone && change.XUID != "" && change.XUID == s.client.userInfo.XUID {
+			removed = true
+		}
+	}
+	s.advanceInitialization()
+	s.arbitrateHost()
+
+	if removed && !s.readOnly {
+		// The caller has been removed from the session by another member.
+		s.closeMu.Lock()
+		s.closeLocked(ErrMemberRemoved)
+		s.closeMu.Unlock()
+	}
+}
+
+// memberState describes the state of a member at the time of a notification.
+type memberState struct {
+	xuid   string
+	status MemberStatus
+}
+
+// memberStates returns the state of each non-nil member in d.
+func memberStates(d SessionDescription) map[string]memberState {
+	states := make(map[string]memberState, len(d.Members))
+	for label, member := range d.Members {
+		if member == nil {
+			continue
+		}
+		state := memberState{status: member.Status()}
+		if member.Constants != nil && member.Constants.System != nil {
+			state.xuid = member.Constants.System.XUID
+		}
+		states[label] = state
+	}
+	return states
+}
+
+// memberChanges compares the cached members with the members reported on the last
+// notification and returns the changes sorted by label. The cached members are
+// recorded as reported.
+func (s *Session) memberChanges() []MemberChange {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+
+	s.cacheMu.RLock()
+	current := memberStates(s.cache)
+	s.cacheMu.RUnlock()
+
+	var changes []MemberChange
+	for label, state := range current {
+		if previous, ok := s.reported[label]; !ok || previous.status != state.status {
+			changes = append(changes, MemberChange{
+				Label:    label,
+				XUID:     state.xuid,
+				Previous: previous.status,
+				Current:  state.status,
+			})
+		}
+	}
+	for label, previous := range s.reported {
+		if _, ok := current[label]; !ok {
+			changes = append(changes, MemberChange{
+				Label:    label,
+				XUID:     previous.xuid,
+				Previous: previous.status,
+				Current:  MemberStatusNone,
+			})
+		}
+	}
+	s.reported = current
+	slices.SortFunc(changes, func(a, b MemberChange) int {
+		return strings.Compare(a.Label, b.Label)
+	})
+	return changes
+}
+
 // update commits partial changes to the session resource identified by the given URL.
-// The provided [SessionDescription] is treated as a patch and merged server-side.
+// The provided changes, typically a [SessionDescription] or a sessionPatch, are
+// treated as a patch and merged server-side.
 // The [context.Context] is used for making a PUT request call.
 //
 // On 200 OK, the local cache and stored ETag are updated from the returned
@@ -156,10 +404,27 @@ func (s *Session) handler() Handler {
 // On 204 No Content, MPSD documents that the session was deleted as a result
 // of the PUT. In that case, deleted is true and the caller is responsible for
 // transitioning the local Session into a deleted/closed state.
-func (s *Session) update(ctx context.Context, changes SessionDescription, opts []internal.RequestOption) (deleted bool, err error) {
+func (s *Session) update(ctx context.Context, changes any, opts []internal.RequestOption) (deleted bool, err error) {
+	return s.updateMatch(ctx, changes, "*", opts)
+}
+
+// updateMatch is like [Session.update], but the changes are only committed if the remote
+// session matches the ETag. If the session has been modified since, an error matching
+// [ErrConflict] is returned and the cache is left unchanged.
+func (s *Session) updateMatch(ctx context.Context, changes any, etag string, opts []internal.RequestOption) (deleted bool, err error) {
+	return s.updateAs(ctx, s.client.client, changes, etag, opts)
+}
+
+// updateAs is like [Session.updateMatch], but the request is made using the HTTP client,
+// which authenticates as the user whose member is referred to by the label "me" in changes.
+// It is used to commit changes on behalf of local members added by [Session.AddLocalMember].
+func (s *Session) updateAs(ctx context.Context, client *http.Client, changes any, etag string, opts []internal.RequestOption) (deleted bool, err error) {
 	s.syncMu.Lock()
 	defer s.syncMu.Unlock()
 
+	if s.readOnly {
+		return false, ErrReadOnly
+	}
 	select {
 	case <-s.closed:
 		return false, net.ErrClosed
@@ -168,14 +433,14 @@ func (s *Session) update(ctx context.Context, changes SessionDescription, opts [
 
 	req, err := internal.WithJSONBody(ctx, http.MethodPut, s.ref.URL().String(), changes, append(opts,
 		internal.RequestHeader("Content-Type", "application/json"),
-		internal.RequestHeader("If-Match", "*"),
+		internal.RequestHeader("If-Match", etag),
 		internal.ContractVersion(contractVersion),
 	))
 	if err != nil {
 		return false, fmt.Errorf("make request: %w", err)
 	}
 
-	resp, err := s.client.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return false, err
 	}
@@ -187,16 +452,38 @@ func (s *Session) update(ctx context.Context, changes SessionDescription, opts [
 	case http.StatusNoContent:
 		return true, nil
 	default:
-		return false, internal.UnexpectedStatusCode(resp)
+		return false, responseError(resp)
 	}
 }
 
-// markDeletedLocked finalizes the local Session after MPSD reports that the
+var (
+	// ErrSessionLeft is the cause of [Session.Context] after the caller has left the
+	// multiplayer session using [Session.Leave] or [Session.Close].
+	ErrSessionLeft = errors.New("mpsd: left the session")
+	// ErrSessionDeleted is the cause of [Session.Context] after the multiplayer session
+	// has been deleted, either by [Session.Delete] or by the directory.
+	ErrSessionDeleted = errors.New("mpsd: session was deleted")
+	// ErrSessionNotDeleted is returned by [Session.Delete] when the caller has been removed
+	// from the multiplayer session, but the session has been kept for other members.
+	ErrSessionNotDeleted = errors.New("mpsd: session was not deleted")
+	// ErrMemberRemoved is the cause of [Session.Context] after the caller has been removed
+	// from the multiplayer session by another member, such as the host kicking the caller.
+	ErrMemberRemoved = errors.New("mpsd: removed from the session")
+	// ErrSubscriptionLost is the cause of [Session.Context] after the RTA subscription
+	// used for receiving notifications for the multiplayer session has been lost.
+	ErrSubscriptionLost = errors.New("mpsd: subscription lost")
+	// ErrReconcileFailed is the cause of [Session.Context] after the connection ID of the
+	// caller in the multiplayer session could not be updated to the current RTA connection ID.
+	ErrReconcileFailed = errors.New("mpsd: connection ID reconcile failed")
+)
+
+// markDeleted finalizes the local Session after MPSD reports that the
 // remote session no longer exists.
 //
 // It clears the cached session data and ETag, unregisters the Session from its
 // parent Client so it no longer receives RTA updates, and closes s.closed so
-// future operations fail as if the Session had been closed.
+// future operations fail as if the Session had been closed. The cause of the
+// context of the Session is [ErrSessionDeleted].
 func (s *Session) markDeleted() {
 	s.closeMu.Lock()
 	defer s.closeMu.Unlock()
@@ -210,7 +497,7 @@ func (s *Session) markDeletedLocked() {
 	s.etag = ""
 	s.cacheMu.Unlock()
 
-	s.closeLocked()
+	s.closeLocked(ErrSessionDeleted)
 }
 
 // closeLocked finalizes the local Session after it is no longer usable by the
@@ -218,53 +505,44 @@ func (s *Session) markDeletedLocked() {
 //
 // It unregisters the Session from its parent Client so it no longer receives
 // RTA updates and closes s.closed so future operations fail as if the Session
-// had been closed.
-func (s *Session) closeLocked() {
+// had been closed. The context of the Session is canceled with the cause. If the
+// Session has already been closed, the cause is ignored.
+func (s *Session) closeLocked(cause error) {
 	s.client.handleSessionClose(s)
+
+	s.ctxMu.Lock()
+	defer s.ctxMu.Unlock()
 	select {
 	case <-s.closed:
 	default:
+		s.cause = cause
 		close(s.closed)
+		if s.cancel != nil {
+			s.cancel(cause)
+		}
 	}
 }
 
 // Context returns a [context.Context] bound to the lifecycle of the Session.
 // Callers can use this context as the parent context for making calls involving the multiplayer
 // session so they can no longer reference a multiplayer session that is closed.
+//
+// Once the Session is closed, the context is canceled and [context.Cause] reports why the
+// Session has been closed, which may be matched against [ErrSessionLeft], [ErrSessionDeleted],
+// [ErrMemberRemoved], [ErrSubscriptionLost] or [ErrReconcileFailed] using [errors.Is].
 func (s *Session) Context() context.Context {
-	return sessionContext{closed: s.closed}
-}
-
-// sessionContext implements [context.Context] that is valid for the lifecycle
-// of a multiplayer session. It is returned by [Session.Context] so callers can
-// use the context as the parent context for making calls involving the multiplayer
-// session.
-type sessionContext struct{ closed <-chan struct{} }
-
-// Deadline implements [context.Context.Deadline]. It always returns zero time with false.
-func (sessionContext) Deadline() (deadline time.Time, ok bool) {
-	return deadline, ok
-}
-
-// Done returns a channel that is closed when the multiplayer session is no longer usable.
-func (ctx sessionContext) Done() <-chan struct{} {
-	return ctx.closed
-}
-
-// Err returns [context.Canceled] if the multiplayer session has been closed, or nil if the
-// underlying multiplayer session is still usable.
-func (ctx sessionContext) Err() error {
-	select {
-	case <-ctx.closed:
-		return context.Canceled
-	default:
-		return nil
+	s.ctxMu.Lock()
+	defer s.ctxMu.Unlock()
+
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancelCause(context.Background())
+		select {
+		case <-s.closed:
+			s.cancel(s.cause)
+		default:
+		}
 	}
-}
-
-// Value implements [context.Context.Value]. It always returns nil for any key.
-func (sessionContext) Value(any) any {
-	return nil
+	return s.ctx
 }
 
 // Sync reconciles the local session state with the remote session state.
@@ -272,6 +550,14 @@ func (sessionContext) Value(any) any {
 // is kept up-to-date automatically though RTA subscription.
 // The request uses the current ETag to perform a conditional GET when possible.
 func (s *Session) Sync(ctx context.Context) error {
+	return s.fetch(ctx, true)
+}
+
+// fetch retrieves the remote session state and updates the cache. If conditional
+// is true, the current ETag is used to perform a conditional GET so that the
+// session body is only returned if it has been changed. Otherwise, the whole session
+// is retrieved regardless of the cached state.
+func (s *Session) fetch(ctx context.Context, conditional bool) error {
 	s.syncMu.Lock()
 	defer s.syncMu.Unlock()
 
@@ -281,9 +567,12 @@ func (s *Session) Sync(ctx context.Context) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		s.cacheMu.RLock()
-		etag := s.etag
-		s.cacheMu.RUnlock()
+		var etag string
+		if conditional {
+			s.cacheMu.RLock()
+			etag = s.etag
+			s.cacheMu.RUnlock()
+		}
 
 		req, err := internal.NewRequest(ctx, http.MethodGet, s.ref.URL().String(), nil, []internal.RequestOption{
 			internal.RequestHeader("Accept", "application/json"),
@@ -306,7 +595,7 @@ func (s *Session) Sync(ctx context.Context) error {
 		case http.StatusNotModified:
 			return nil
 		default:
-			return internal.UnexpectedStatusCode(resp)
+			return responseError(resp)
 		}
 	}
 }
@@ -340,13 +629,21 @@ func (s *Session) sync(resp *http.Response) error {
 // SetCustomProperties commits the custom properties to the multiplayer session.
 // The format or semantics of the custom data is specific to the title. It is
 // commonly used to expose session metadata such as display names or
-// server details.
+// server details. The custom properties are replaced as a whole; use
+// [Session.PatchCustomProperties] to change only some of the fields.
 func (s *Session) SetCustomProperties(ctx context.Context, custom json.RawMessage, opts ...internal.RequestOption) error {
-	deleted, err := s.update(ctx, SessionDescription{
+	return s.commit(ctx, SessionDescription{
 		Properties: &SessionProperties{
 			Custom: custom,
 		},
 	}, opts)
+}
+
+// commit commits the changes to the multiplayer session using [Session.update].
+// If MPSD reports that the session was deleted as a result of the changes,
+// the Session is marked as deleted and no error is returned.
+func (s *Session) commit(ctx context.Context, changes any, opts []internal.RequestOption) error {
+	deleted, err := s.update(ctx, changes, opts)
 	if err != nil {
 		return err
 	}
@@ -384,6 +681,18 @@ func (s *Session) Properties() SessionProperties {
 	return *cloneSessionProperties(properties)
 }
 
+// Initializing returns the current initialization episode of the multiplayer session.
+// The boolean result reports whether the session is currently initializing.
+func (s *Session) Initializing() (SessionInitializing, bool) {
+	s.cacheMu.RLock()
+	defer s.cacheMu.RUnlock()
+
+	if s.cache.Initializing == nil {
+		return SessionInitializing{}, false
+	}
+	return *s.cache.Initializing, true
+}
+
 // Reference returns a reference to the multiplayer session.
 // Callers may use this method for referencing the Session in external services in the game.
 func (s *Session) Reference() SessionReference {
@@ -456,7 +765,7 @@ func (s *Session) Members() iter.Seq2[string, MemberDescription] {
 // The [context.Context] is used for making a PUT request call. Changes are commited
 // immediately and reflected in the local cache.
 func (s *Session) SetMemberCustomProperties(ctx context.Context, label string, custom json.RawMessage, opts ...internal.RequestOption) error {
-	deleted, err := s.update(ctx, SessionDescription{
+	return s.commit(ctx, SessionDescription{
 		Members: map[string]*MemberDescription{
 			label: {
 				Properties: &MemberProperties{
@@ -465,13 +774,6 @@ func (s *Session) SetMemberCustomProperties(ctx context.Context, label string, c
 			},
 		},
 	}, opts)
-	if err != nil {
-		return err
-	}
-	if deleted {
-		s.markDeleted()
-	}
-	return nil
 }
 
 // SessionReference encapsulates a reference to a multiplayer session.
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -48,11 +49,15 @@ func TestSessionConstantsReturnsDetachedCopy(t *testing.T) {
 
 	constants := session.Constants()
 	constants.System.Initiators[0] = "mutated-initiator"
+	constants.System.Capabilities.Gameplay = false
 	constants.Custom[0] = 'X'
 
 	if got := session.cache.Constants.System.Initiators[0]; got != "original-initiator" {
 		t.Fatalf("cache initiators mutated: got %q", got)
 	}
+	if !session.cache.Constants.System.Capabilities.Gameplay {
+		t.Fatal("cache capabilities mutated")
+	}
 	if got := string(session.cache.Constants.Custom); got != `{"constant":"original"}` {
 		t.Fatalf("cache constants custom mutated: got %s", got)
 	}
@@ -116,7 +121,7 @@ func testSessionWithCache() *Session {
 				System: &SessionConstantsSystem{
 					Visibility:   "open",
 					Initiators:   []string{"original-initiator"},
-					Capabilities: json.RawMessage(`{"capability":true}`),
+					Capabilities: &SessionCapabilities{Gameplay: true},
 				},
 				Custom: json.RawMessage(`{"constant":"original"}`),
 			},
@@ -813,3 +818,1346 @@ func (s *blockingSubscriber) Subscribe(context.Context, *rta.Subscription) error
 	}
 	return errors.New("subscribe failed")
 }
+
+func TestSessionHostControlsEncodeOnlyChangedProperties(t *testing.T) {
+	bodies := make(chan map[string]any, 1)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		bodies <- body
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})}
+	session := testSessionWithCache()
+	session.client = &Client{client: httpClient}
+	session.ref = SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "SESSION"}
+	session.closed = make(chan struct{})
+
+	if err := session.SetClosed(context.Background(), false); err != nil {
+		t.Fatalf("SetClosed returned error: %v", err)
+	}
+	system := (<-bodies)["properties"].(map[string]any)["system"].(map[string]any)
+	if len(system) != 1 || system["closed"] != false {
+		t.Fatalf("system properties = %v, want only closed=false", system)
+	}
+}
+
+func TestSessionHostControlsValidateBeforeRequest(t *testing.T) {
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("unexpected request")
+	})}
+	session := testSessionWithCache()
+	session.client = &Client{client: httpClient}
+	session.closed = make(chan struct{})
+	session.cache.Constants.System.MaxMembersCount = 1
+
+	if err := session.RemoveMember(context.Background(), "7"); !errors.Is(err, ErrMemberNotFound) {
+		t.Fatalf("RemoveMember error = %v, want %v", err, ErrMemberNotFound)
+	}
+	if err := session.SetTurn(context.Background(), []uint32{0, 1}); !errors.Is(err, ErrTooManyMembers) {
+		t.Fatalf("SetTurn error = %v, want %v", err, ErrTooManyMembers)
+	}
+	if err := session.SetJoinRestriction(context.Background(), "everyone"); !errors.Is(err, ErrInvalidRestriction) {
+		t.Fatalf("SetJoinRestriction error = %v, want %v", err, ErrInvalidRestriction)
+	}
+}
+
+func TestSessionReserveInitializesOnlyWhenRequested(t *testing.T) {
+	bodies := make(chan SessionDescription, 2)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body SessionDescription
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		bodies <- body
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})}
+	session := testSessionWithCache()
+	session.client = &Client{client: httpClient}
+	session.ref = SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "SESSION"}
+	session.closed = make(chan struct{})
+
+	for _, initialize := range []bool{false, true} {
+		if err := session.Reserve(context.Background(), []string{"2"}, ReserveConfig{Initialize: initialize}); err != nil {
+			t.Fatalf("Reserve returned error: %v", err)
+		}
+		member := (<-bodies).Members["reserve_0"]
+		if member == nil || member.Constants == nil || member.Constants.System == nil {
+			t.Fatalf("reserved member = %+v, want system constants", member)
+		}
+		if got := member.Constants.System.Initialize; got != initialize {
+			t.Fatalf("Initialize = %v, want %v", got, initialize)
+		}
+	}
+}
+
+func TestSessionMemberChangesReportReservations(t *testing.T) {
+	session := testSessionWithCache()
+	session.reported = memberStates(session.cache)
+	session.cache.Members["1"] = &MemberDescription{
+		Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "reserved-xuid"}},
+		Reserved:  true,
+	}
+
+	changes := session.memberChanges()
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v, want one reservation", changes)
+	}
+	if got := changes[0]; got.Label != "1" || got.XUID != "reserved-xuid" || got.Previous != MemberStatusNone || got.Current != MemberStatusReserved {
+		t.Fatalf("change = %+v, want reservation of member 1", got)
+	}
+
+	session.cache.Members["1"].Reserved = false
+	session.cache.Members["1"].Properties = &MemberProperties{System: &MemberPropertiesSystem{Active: true}}
+	changes = session.memberChanges()
+	if len(changes) != 1 || changes[0].Previous != MemberStatusReserved || changes[0].Current != MemberStatusActive {
+		t.Fatalf("changes = %+v, want reserved member becoming active", changes)
+	}
+	if changes := session.memberChanges(); len(changes) != 0 {
+		t.Fatalf("changes = %+v, want none after reporting", changes)
+	}
+}
+
+func TestAttachRejectsNonMember(t *testing.T) {
+	var requests atomic.Int32
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		if req.Method != http.MethodGet {
+			return nil, fmt.Errorf("request method = %s, want GET", req.Method)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body: io.NopCloser(bytes.NewReader([]byte(`{
+				"members": {
+					"0": {"constants": {"system": {"xuid": "2"}}}
+				}
+			}`))),
+			Header:  make(http.Header),
+			Request: req,
+		}, nil
+	})}
+	client := New(httpClient, nil, xsts.UserInfo{XUID: "1"}, nil)
+
+	_, err := client.Attach(context.Background(), SessionReference{
+		ServiceConfigID: uuid.New(),
+		TemplateName:    "template",
+		Name:            "SESSION",
+	})
+	if !errors.Is(err, ErrNotMember) {
+		t.Fatalf("Attach error = %v, want %v", err, ErrNotMember)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("requests = %d, want 1", got)
+	}
+}
+
+func TestAttachReturnsSessionAttachedConcurrently(t *testing.T) {
+	ref := SessionReference{
+		ServiceConfigID: uuid.New(),
+		TemplateName:    "template",
+		Name:            "SESSION",
+	}
+	var (
+		client   *Client
+		attached *Session
+		requests atomic.Int32
+	)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		if req.Method != http.MethodGet {
+			return nil, fmt.Errorf("request method = %s, want GET", req.Method)
+		}
+		// Simulate a Publish or Join call that registers the session while it is being retrieved.
+		attached = client.newSession(ref, SessionDescription{}, "")
+		client.sessionsMu.Lock()
+		client.sessions[ref.URL().String()] = attached
+		client.sessionsMu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body: io.NopCloser(bytes.NewReader([]byte(`{
+				"members": {
+					"0": {"constants": {"system": {"xuid": "1"}}}
+				}
+			}`))),
+			Header:  make(http.Header),
+			Request: req,
+		}, nil
+	})}
+	client = New(httpClient, nil, xsts.UserInfo{XUID: "1"}, nil)
+
+	s, err := client.Attach(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+	if s != attached {
+		t.Fatal("Attach returned a new Session, want the Session attached concurrently")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("requests = %d, want 1", got)
+	}
+}
+
+func TestAttachFailureDoesNotLeaveSession(t *testing.T) {
+	ref := SessionReference{
+		ServiceConfigID: uuid.New(),
+		TemplateName:    "template",
+		Name:            "SESSION",
+	}
+	var (
+		gets    atomic.Int32
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.Method {
+		case http.MethodGet:
+			if gets.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body: io.NopCloser(bytes.NewReader([]byte(`{
+					"members": {
+						"0": {"constants": {"system": {"xuid": "1"}}}
+					}
+				}`))),
+				Header:  make(http.Header),
+				Request: req,
+			}, nil
+		case http.MethodPost:
+			// Publishing the activity handle fails transiently.
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     http.StatusText(http.StatusServiceUnavailable),
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+				Header:     make(http.Header),
+				Request:    req,
+			}, nil
+		default:
+			return nil, fmt.Errorf("unexpected %s request, the caller must not leave the session", req.Method)
+		}
+	})}
+	client := New(httpClient, nil, xsts.UserInfo{XUID: "1"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := client.Attach(context.Background(), ref)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		// A concurrent call waits for the result of the call in flight.
+		_, err := client.Attach(context.Background(), ref)
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	first, second := <-errs, <-errs
+	if first == nil || first != second {
+		t.Fatalf("Attach errors = %v and %v, want the same error", first, second)
+	}
+	if got := gets.Load(); got != 1 {
+		t.Fatalf("GET requests = %d, want 1", got)
+	}
+	if n := len(client.sessions); n != 0 {
+		t.Fatalf("%d sessions tracked after failed Attach, want 0", n)
+	}
+}
+
+func TestPollInvitesDeliversUnseenInvitesInOrder(t *testing.T) {
+	seenID, firstID, secondID := uuid.New(), uuid.New(), uuid.New()
+	var client *Client
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// The invite state must not be locked while the directory is queried.
+		if !client.invitesMu.TryLock() {
+			return nil, errors.New("invites locked while querying the directory")
+		}
+		client.invitesMu.Unlock()
+
+		body, err := json.Marshal(map[string]any{
+			"results": []map[string]any{{"id": firstID}, {"id": seenID}, {"id": secondID}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})}
+
+	var delivered []uuid.UUID
+	client = New(httpClient, nil, xsts.UserInfo{XUID: "1"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	client.inviteHandler = inviteFunc(func(handle InviteHandle) { delivered = append(delivered, handle.ID) })
+	client.seenInvites = map[uuid.UUID]struct{}{seenID: {}}
+
+	if err := client.PollInvites(context.Background()); err != nil {
+		t.Fatalf("PollInvites: %v", err)
+	}
+	if want := []uuid.UUID{firstID, secondID}; !slices.Equal(delivered, want) {
+		t.Fatalf("delivered invites = %v, want %v", delivered, want)
+	}
+
+	// Invites that have already been delivered are not delivered again.
+	delivered = nil
+	if err := client.PollInvites(context.Background()); err != nil {
+		t.Fatalf("PollInvites: %v", err)
+	}
+	if len(delivered) != 0 {
+		t.Fatalf("delivered invites = %v, want none", delivered)
+	}
+}
+
+func TestShoulderTapForUntrackedSessionPollsInvites(t *testing.T) {
+	inviteID := uuid.New()
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := json.Marshal(map[string]any{
+			"results": []map[string]any{{"id": inviteID}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})}
+
+	delivered := make(chan uuid.UUID, 1)
+	client := New(httpClient, nil, xsts.UserInfo{XUID: "1"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	client.inviteHandler = inviteFunc(func(handle InviteHandle) { delivered <- handle.ID })
+	client.seenInvites = map[uuid.UUID]struct{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The interval is long enough that only the shoulder tap triggers a poll.
+	go client.pollInvites(ctx, time.Hour)
+
+	handler := &subscriptionHandler{Client: client, log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	data, err := json.Marshal(subscriptionEvent{ShoulderTaps: []shoulderTap{{
+		Resource:     uuid.NewString() + "~template~session",
+		ChangeNumber: 1,
+		Branch:       uuid.New(),
+	}}})
+	if err != nil {
+		t.Fatalf("encode event: %v", err)
+	}
+	handler.HandleEvent(data)
+
+	select {
+	case got := <-delivered:
+		if got != inviteID {
+			t.Fatalf("delivered invite = %v, want %v", got, inviteID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("invite was not delivered after a shoulder tap for an untracked session")
+	}
+}
+
+type inviteFunc func(InviteHandle)
+
+func (f inviteFunc) HandleInvite(handle InviteHandle) { f(handle) }
+
+func TestSessionInviteManyReportsPerRecipientResults(t *testing.T) {
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body inviteHandle
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if body.InviteAttributes.Context != "lobby" {
+			return nil, fmt.Errorf("invite context = %q, want lobby", body.InviteAttributes.Context)
+		}
+		resp := &http.Response{
+			Header:  make(http.Header),
+			Request: req,
+			Body:    io.NopCloser(bytes.NewReader([]byte(`{"id":"` + uuid.NewString() + `","invitedXuid":"` + body.InvitedXUID + `"}`))),
+		}
+		switch body.InvitedXUID {
+		case "blocked":
+			resp.StatusCode = http.StatusForbidden
+			resp.Body = http.NoBody
+		case "missing":
+			resp.StatusCode = http.StatusNotFound
+			resp.Body = http.NoBody
+		case "throttled":
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Header.Set("Retry-After", "3")
+		default:
+			resp.StatusCode = http.StatusCreated
+		}
+		resp.Status = http.StatusText(resp.StatusCode)
+		return resp, nil
+	})}
+	session := &Session{
+		client: &Client{client: httpClient},
+		ref:    SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "SESSION"},
+		closed: make(chan struct{}),
+	}
+
+	results := session.InviteMany(context.Background(), []string{"ok", "blocked", "missing", "throttled", "ok"}, InviteOptions{
+		TitleID:     "123",
+		Context:     "lobby",
+		Concurrency: 2,
+	})
+	if len(results) != 4 {
+		t.Fatalf("results = %d, want 4", len(results))
+	}
+	if result := results["ok"]; result.Err != nil || result.Handle == nil || result.Handle.InvitedXUID != "ok" {
+		t.Fatalf("ok result = %+v, want invite handle", result)
+	}
+	if err := results["blocked"].Err; !errors.Is(err, ErrPrivacyBlocked) {
+		t.Fatalf("blocked error = %v, want %v", err, ErrPrivacyBlocked)
+	}
+	if err := results["missing"].Err; err == nil || errors.Is(err, ErrPrivacyBlocked) {
+		t.Fatalf("missing error = %v, want error not matching %v", err, ErrPrivacyBlocked)
+	}
+	// A 403 Forbidden response for a session the caller cannot access is not a privacy block.
+	if err := (&ResponseError{
+		Method:     http.MethodGet,
+		URL:        SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "SESSION"}.URL().String(),
+		StatusCode: http.StatusForbidden,
+	}); errors.Is(err, ErrPrivacyBlocked) {
+		t.Fatalf("session error = %v, want error not matching %v", err, ErrPrivacyBlocked)
+	}
+	var responseErr *ResponseError
+	if err := results["throttled"].Err; !errors.Is(err, ErrThrottled) || !errors.As(err, &responseErr) || responseErr.RetryAfter != 3*time.Second {
+		t.Fatalf("throttled error = %v, want %v with retry after", err, ErrThrottled)
+	}
+}
+
+func TestSessionInviteChecksPermissions(t *testing.T) {
+	var invited []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body inviteHandle
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		invited = append(invited, body.InvitedXUID)
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     make(http.Header),
+			Request:    req,
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{"id":"` + uuid.NewString() + `","invitedXuid":"` + body.InvitedXUID + `"}`))),
+		}, nil
+	})}
+	session := &Session{
+		client: &Client{client: httpClient},
+		ref:    SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "SESSION"},
+		closed: make(chan struct{}),
+	}
+
+	denied := errors.New("denied")
+	checker := permissionCheckerFunc(func(_ context.Context, xuid string) error {
+		if xuid == "blocked" {
+			return denied
+		}
+		return nil
+	})
+	results := session.InviteMany(context.Background(), []string{"blocked", "ok"}, InviteOptions{
+		TitleID:           "123",
+		PermissionChecker: checker,
+	})
+	if err := results["blocked"].Err; !errors.Is(err, denied) {
+		t.Fatalf("blocked error = %v, want %v", err, denied)
+	}
+	if err := results["ok"].Err; err != nil {
+		t.Fatalf("ok error = %v, want nil", err)
+	}
+	if _, err := session.InviteWithOptions(context.Background(), "blocked", InviteOptions{
+		TitleID:           "123",
+		PermissionChecker: checker,
+	}); !errors.Is(err, denied) {
+		t.Fatalf("InviteWithOptions error = %v, want %v", err, denied)
+	}
+	// The checker only applies to the call it was passed to.
+	if _, err := session.Invite(context.Background(), "blocked", "123"); err != nil {
+		t.Fatalf("Invite without checker: %v", err)
+	}
+	if !slices.Equal(invited, []string{"ok", "blocked"}) {
+		t.Fatalf("invited = %v, want [ok blocked]", invited)
+	}
+}
+
+type permissionCheckerFunc func(context.Context, string) error
+
+func (f permissionCheckerFunc) CheckInvitePermission(ctx context.Context, xuid string) error {
+	return f(ctx, xuid)
+}
+
+func TestSubscriptionHandlerCoalescesShoulderTaps(t *testing.T) {
+	ref := SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "name"}
+	branch := uuid.New()
+
+	var (
+		requests    atomic.Int32
+		conditional = make(chan bool, 8)
+		started     = make(chan struct{}, 8)
+		release     = make(chan struct{})
+		changes     = make(chan uint64, 8)
+	)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet {
+			return nil, fmt.Errorf("request method = %s, want GET", req.Method)
+		}
+		requests.Add(1)
+		conditional <- req.Header.Get("If-None-Match") != ""
+		started <- struct{}{}
+		<-release
+		body, err := json.Marshal(SessionDescription{Branch: branch, ChangeNumber: <-changes})
+		if err != nil {
+			return nil, err
+		}
+		header := make(http.Header)
+		header.Set("ETag", `"etag"`)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: header, Request: req}, nil
+	})}
+
+	client := &Client{client: httpClient, sessions: map[string]*Session{}}
+	notified := make(chan uint64, 8)
+	session := &Session{
+		client: client,
+		ref:    ref,
+		etag:   `"etag"`,
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		h: sessionChangeFunc(func(session *Session) {
+			_, changeNumber := session.version()
+			notified <- changeNumber
+		}),
+		cache:  SessionDescription{Branch: branch, ChangeNumber: 5},
+		closed: make(chan struct{}),
+	}
+	client.sessions[ref.URL().String()] = session
+	handler := &subscriptionHandler{Client: client, log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	event := func(changeNumber uint64) json.RawMessage {
+		data, err := json.Marshal(subscriptionEvent{ShoulderTaps: []shoulderTap{{
+			Resource:     ref.ServiceConfigID.String() + "~" + ref.TemplateName + "~" + ref.Name,
+			ChangeNumber: changeNumber,
+			Branch:       branch,
+		}}})
+		if err != nil {
+			t.Fatalf("encode event: %v", err)
+		}
+		return data
+	}
+
+	// A tap for the cached change number is stale.
+	handler.HandleEvent(event(5))
+
+	handler.HandleEvent(event(6))
+	<-started
+	// Taps received while the first sync is in flight are coalesced and are
+	// already reflected in the session returned by the first sync.
+	handler.HandleEvent(event(7))
+	handler.HandleEvent(event(8))
+	changes <- 8
+	close(release)
+	if got := <-notified; got != 8 {
+		t.Fatalf("notified change number = %d, want 8", got)
+	}
+	if !<-conditional {
+		t.Fatal("first sync was not conditional")
+	}
+
+	// A tap skipping a change number forces a full resync.
+	changes <- 10
+	handler.HandleEvent(event(10))
+	if got := <-notified; got != 10 {
+		t.Fatalf("notified change number = %d, want 10", got)
+	}
+	if <-conditional {
+		t.Fatal("sync after gap was conditional")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+}
+
+func TestWatchPollsReadOnlySession(t *testing.T) {
+	ref := SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "name"}
+	var gets atomic.Int32
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet {
+			t.Errorf("request method = %s, want GET", req.Method)
+			return nil, errors.New("unexpected request")
+		}
+		switch gets.Add(1) {
+		case 1, 2:
+			body, err := json.Marshal(SessionDescription{
+				ChangeNumber: uint64(gets.Load()),
+				Members: map[string]*MemberDescription{
+					"0": {Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "2"}}},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			header := make(http.Header)
+			header.Set("ETag", fmt.Sprintf(`"%d"`, gets.Load()))
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: header, Request: req}, nil
+		default:
+			return &http.Response{StatusCode: http.StatusNotFound, Status: http.StatusText(http.StatusNotFound), Body: http.NoBody, Request: req}, nil
+		}
+	})}
+	client := New(httpClient, nil, xsts.UserInfo{XUID: "1"}, nil)
+
+	changed := make(chan uint64, 1)
+	session, err := client.Watch(context.Background(), ref, sessionChangeFunc(func(session *Session) {
+		_, changeNumber := session.version()
+		changed <- changeNumber
+	}), WatchConfig{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if _, ok := session.MemberByXUID("2"); !ok {
+		t.Fatal("watched session does not include member")
+	}
+	if err := session.SetCustomProperties(context.Background(), json.RawMessage(`{}`)); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("SetCustomProperties error = %v, want %v", err, ErrReadOnly)
+	}
+	if _, err := session.Invite(context.Background(), "3", "title"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Invite error = %v, want %v", err, ErrReadOnly)
+	}
+
+	select {
+	case got := <-changed:
+		if got != 2 {
+			t.Fatalf("notified change number = %d, want 2", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watched session was not notified of change")
+	}
+	select {
+	case <-session.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("watched session was not closed after it was deleted")
+	}
+}
+
+func TestValidationRejectsBeforeWrite(t *testing.T) {
+	scid := uuid.New()
+	handleID := uuid.New()
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body string
+		switch req.URL.Path {
+		case "/serviceconfigs/" + scid.String() + "/sessiontemplates/template":
+			body = `{"contractVersion":107,"constants":{"system":{"maxMembersCount":4},"custom":{"mode":"survival"}}}`
+		case "/handles/" + handleID.String():
+			body = `{"id":"` + handleID.String() + `","sessionRef":{"scid":"` + scid.String() + `","templateName":"template","name":"name"},"relatedInfo":{"membersCount":4}}`
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, req.URL)
+			return nil, errors.New("unexpected request")
+		}
+		if req.Method != http.MethodGet {
+			t.Errorf("request method = %s, want GET", req.Method)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(body))), Request: req}, nil
+	})}
+	client := New(httpClient, nil, xsts.UserInfo{XUID: "1"}, nil)
+	client.subscriptionData.Store(&subscriptionData{ConnectionID: uuid.New()})
+	client.rta = rta.NewProvider(subscriberFunc(func(context.Context, *rta.Subscription) error { return nil }), nil)
+
+	_, err := client.Publish(context.Background(), SessionReference{ServiceConfigID: scid, TemplateName: "template"}, PublishConfig{
+		CustomConstants: json.RawMessage(`{"mode":"creative"}`),
+		Validate:        true,
+	})
+	if !errors.Is(err, ErrTemplateMismatch) {
+		t.Fatalf("Publish error = %v, want %v", err, ErrTemplateMismatch)
+	}
+	_, err = client.Publish(context.Background(), SessionReference{ServiceConfigID: scid, TemplateName: "template"}, PublishConfig{
+		SystemConstants: &SessionConstantsSystem{MaxMembersCount: 8},
+		Validate:        true,
+	})
+	if !errors.Is(err, ErrTemplateMismatch) {
+		t.Fatalf("Publish error = %v, want %v", err, ErrTemplateMismatch)
+	}
+	_, err = client.Publish(context.Background(), SessionReference{ServiceConfigID: scid, TemplateName: "template"}, PublishConfig{
+		JoinRestriction: "everyone",
+		Validate:        true,
+	})
+	if !errors.Is(err, ErrInvalidRestriction) {
+		t.Fatalf("Publish error = %v, want %v", err, ErrInvalidRestriction)
+	}
+	_, err = client.Join(context.Background(), handleID, JoinConfig{Validate: true})
+	if !errors.Is(err, ErrTooManyMembers) {
+		t.Fatalf("Join error = %v, want %v", err, ErrTooManyMembers)
+	}
+}
+
+func TestValidatePublishSystemConstants(t *testing.T) {
+	template := func(system *SessionConstantsSystem) *SessionTemplate {
+		return &SessionTemplate{Name: "template", Constants: &SessionConstants{System: system}}
+	}
+	tests := []struct {
+		name     string
+		template *SessionTemplate
+		system   *SessionConstantsSystem
+		wantErr  bool
+	}{
+		{
+			name:     "max members count within template capabilities",
+			template: template(&SessionConstantsSystem{Capabilities: &SessionCapabilities{Large: true}}),
+			system:   &SessionConstantsSystem{MaxMembersCount: 500},
+		},
+		{
+			name:     "max members count exceeds template capabilities",
+			template: template(&SessionConstantsSystem{Capabilities: &SessionCapabilities{Connectivity: true}}),
+			system:   &SessionConstantsSystem{MaxMembersCount: 500},
+			wantErr:  true,
+		},
+		{
+			name:     "template max members count without large capability",
+			template: template(&SessionConstantsSystem{MaxMembersCount: 200}),
+			wantErr:  true,
+		},
+		{
+			name:     "known visibility",
+			template: template(&SessionConstantsSystem{MaxMembersCount: 8}),
+			system:   &SessionConstantsSystem{Visibility: SessionVisibilityOpen},
+		},
+		{
+			name:     "visibility overrides template",
+			template: template(&SessionConstantsSystem{Visibility: SessionVisibilityPrivate}),
+			system:   &SessionConstantsSystem{Visibility: SessionVisibilityOpen},
+			wantErr:  true,
+		},
+		{
+			name:     "capabilities override template",
+			template: template(&SessionConstantsSystem{Capabilities: &SessionCapabilities{Gameplay: true}}),
+			system:   &SessionConstantsSystem{Capabilities: &SessionCapabilities{Connectivity: true}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown visibility",
+			template: template(&SessionConstantsSystem{MaxMembersCount: 8}),
+			system:   &SessionConstantsSystem{Visibility: "public"},
+			wantErr:  true,
+		},
+		{
+			name:     "large capability with connectivity",
+			template: template(&SessionConstantsSystem{MaxMembersCount: 8}),
+			system:   &SessionConstantsSystem{Capabilities: &SessionCapabilities{Large: true, Connectivity: true}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePublish(tt.template, PublishConfig{
+				SystemConstants: tt.system,
+				JoinRestriction: SessionRestrictionFollowed,
+				ReadRestriction: SessionRestrictionFollowed,
+			})
+			if tt.wantErr && !errors.Is(err, ErrTemplateMismatch) {
+				t.Fatalf("validatePublish error = %v, want %v", err, ErrTemplateMismatch)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("validatePublish error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestSessionDescriptionDecodesTypedConstants(t *testing.T) {
+	var d SessionDescription
+	if err := json.Unmarshal([]byte(`{
+		"constants": {"system": {
+			"visibility": "open",
+			"capabilities": {"connectivity": true, "gameplay": true, "crossPlay": true},
+			"metrics": {"latency": true},
+			"memberInitialization": {"joinTimeout": 4000, "membersNeededToStart": 2},
+			"peerToHostRequirements": {"latencyMaximum": 250, "hostSelectionMetric": "latency"},
+			"measurementServerAddresses": {"east.azure.com": {"secureDeviceAddress": "r5Y="}}
+		}},
+		"initializing": {"stage": "measuring", "episode": 1},
+		"members": {"0": {"initializationEpisode": 1}}
+	}`), &d); err != nil {
+		t.Fatalf("decode session description: %v", err)
+	}
+	system := d.Constants.System
+	if system.Visibility != SessionVisibilityOpen {
+		t.Fatalf("visibility = %q, want %q", system.Visibility, SessionVisibilityOpen)
+	}
+	if !system.Capabilities.Connectivity || !system.Capabilities.CrossPlay || system.Capabilities.Large {
+		t.Fatalf("capabilities = %+v", system.Capabilities)
+	}
+	if got := system.MemberInitialization.JoinTimeout; got != 4000 {
+		t.Fatalf("join timeout = %d, want 4000", got)
+	}
+	if got := system.PeerToHostRequirements.HostSelectionMetric; got != HostSelectionMetricLatency {
+		t.Fatalf("host selection metric = %q, want %q", got, HostSelectionMetricLatency)
+	}
+	if got := system.MeasurementServerAddresses["east.azure.com"].SecureDeviceAddress; !bytes.Equal(got, []byte{0xaf, 0x96}) {
+		t.Fatalf("secure device address = %x", got)
+	}
+	if d.Initializing == nil || d.Initializing.Stage != InitializationStageMeasuring {
+		t.Fatalf("initializing = %+v", d.Initializing)
+	}
+	if got := d.Members["0"].InitializationEpisode; got != 1 {
+		t.Fatalf("member initialization episode = %d, want 1", got)
+	}
+}
+
+type stubMeasurer struct{}
+
+func (stubMeasurer) MeasureServers(_ context.Context, servers map[string]MeasurementServerAddress) (map[string]ServerMeasurement, error) {
+	measurements := make(map[string]ServerMeasurement, len(servers))
+	for name := range servers {
+		measurements[name] = ServerMeasurement{Latency: 20}
+	}
+	return measurements, nil
+}
+
+func (stubMeasurer) MeasurePeers(_ context.Context, peers map[string]MemberDescription, _ Metrics) (map[string]Measurement, error) {
+	measurements := make(map[string]Measurement, len(peers))
+	for label := range peers {
+		measurements[label] = Measurement{Latency: 40}
+	}
+	return measurements, nil
+}
+
+type initializationFunc func(*Session, InitializationResult)
+
+func (f initializationFunc) HandleInitialization(session *Session, result InitializationResult) {
+	f(session, result)
+}
+
+func TestSessionInitializeUploadsMeasurementsAndReportsResult(t *testing.T) {
+	uploaded := make(chan map[string]any, 1)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPut {
+			return nil, fmt.Errorf("request method = %s, want PUT", req.Method)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		uploaded <- body
+		// Respond with the state of the session after the initialization has succeeded.
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(`{"properties":{"system":{"initializationSucceeded":true}},"members":{"0":{"constants":{"system":{"xuid":"1"}}}}}`))), Header: make(http.Header), Request: req}, nil
+	})}
+
+	member := func(xuid string) *MemberDescription {
+		return &MemberDescription{
+			Constants:             &MemberConstants{System: &MemberConstantsSystem{XUID: xuid, Initialize: true}},
+			InitializationEpisode: 1,
+		}
+	}
+	session := &Session{
+		client: &Client{client: httpClient, userInfo: xsts.UserInfo{XUID: "1"}},
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		h:      NopHandler{},
+		cache: SessionDescription{
+			Constants: &SessionConstants{System: &SessionConstantsSystem{
+				Metrics:                    &Metrics{Latency: true},
+				MeasurementServerAddresses: map[string]MeasurementServerAddress{"east": {}},
+			}},
+			Initializing: &SessionInitializing{Stage: InitializationStageMeasuring, Episode: 1},
+			Members:      map[string]*MemberDescription{"0": member("1"), "1": member("2")},
+		},
+		closed: make(chan struct{}),
+	}
+
+	results := make(chan InitializationResult, 1)
+	session.Initialize(stubMeasurer{}, initializationFunc(func(_ *Session, result InitializationResult) {
+		results <- result
+	}))
+
+	var body map[string]any
+	select {
+	case body = <-uploaded:
+	case <-time.After(time.Second):
+		t.Fatal("measurements were not uploaded")
+	}
+	system := body["members"].(map[string]any)["me"].(map[string]any)["properties"].(map[string]any)["system"].(map[string]any)
+	if _, ok := system["connection"]; ok {
+		t.Fatal("measurement upload overwrites connection")
+	}
+	if got := system["measurements"].(map[string]any)["1"].(map[string]any)["latency"]; got != float64(40) {
+		t.Fatalf("peer latency = %v, want 40", got)
+	}
+	if got := system["serverMeasurements"].(map[string]any)["east"].(map[string]any)["latency"]; got != float64(20) {
+		t.Fatalf("server latency = %v, want 20", got)
+	}
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if _, ok := session.Initializing(); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("session was not updated after uploading measurements")
+		}
+	}
+	session.notify()
+
+	select {
+	case result := <-results:
+		if !result.Succeeded || result.Episode != 1 || result.Err != nil {
+			t.Fatalf("result = %+v, want succeeded episode 1", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("initialization result was not reported")
+	}
+}
+
+type stubEvaluator struct {
+	stubMeasurer
+	succeeded bool
+}
+
+func (e stubEvaluator) Evaluate(_ context.Context, members map[string]MemberDescription) (bool, error) {
+	if len(members) != 2 {
+		return false, fmt.Errorf("evaluated %d members, want 2", len(members))
+	}
+	return e.succeeded, nil
+}
+
+func TestSessionInitializeEvaluatesMeasurements(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		measurer Measurer
+		want     bool
+		wantErr  error
+	}{
+		{name: "succeeded", measurer: stubEvaluator{succeeded: true}, want: true},
+		{name: "failed", measurer: stubEvaluator{}, want: false},
+		{name: "unsupported", measurer: stubMeasurer{}, want: false, wantErr: ErrEvaluationUnsupported},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			uploaded := make(chan map[string]any, 1)
+			httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				var body map[string]any
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+				uploaded <- body
+				succeeded := body["properties"].(map[string]any)["system"].(map[string]any)["initializationSucceeded"] == true
+				// Respond with the state of the session after the initialization has finished.
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(fmt.Sprintf(`{"properties":{"system":{"initializationSucceeded":%t}},"members":{"0":{"constants":{"system":{"xuid":"1"}}}}}`, succeeded)))), Header: make(http.Header), Request: req}, nil
+			})}
+
+			member := func(xuid string) *MemberDescription {
+				return &MemberDescription{
+					Constants:             &MemberConstants{System: &MemberConstantsSystem{XUID: xuid, Initialize: true}},
+					InitializationEpisode: 1,
+				}
+			}
+			session := &Session{
+				client: &Client{client: httpClient, userInfo: xsts.UserInfo{XUID: "1"}},
+				log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+				h:      NopHandler{},
+				cache: SessionDescription{
+					Constants: &SessionConstants{System: &SessionConstantsSystem{
+						MemberInitialization: &MemberInitialization{ExternalEvaluation: true},
+					}},
+					Initializing: &SessionInitializing{Stage: InitializationStageEvaluating, Episode: 1},
+					Members:      map[string]*MemberDescription{"0": member("1"), "1": member("2")},
+				},
+				closed: make(chan struct{}),
+			}
+
+			results := make(chan InitializationResult, 1)
+			session.Initialize(tt.measurer, initializationFunc(func(_ *Session, result InitializationResult) {
+				results <- result
+			}))
+
+			var body map[string]any
+			select {
+			case body = <-uploaded:
+			case <-time.After(time.Second):
+				t.Fatal("evaluation result was not uploaded")
+			}
+			system := body["properties"].(map[string]any)["system"].(map[string]any)
+			if got := system["initializationSucceeded"]; got != tt.want {
+				t.Fatalf("uploaded initializationSucceeded = %v, want %t", got, tt.want)
+			}
+
+			for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+				if _, ok := session.Initializing(); !ok {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("session was not updated after uploading the evaluation result")
+				}
+			}
+			session.notify()
+
+			select {
+			case result := <-results:
+				if result.Succeeded != tt.want || result.Episode != 1 || !errors.Is(result.Err, tt.wantErr) {
+					t.Fatalf("result = %+v, want succeeded %t with error %v", result, tt.want, tt.wantErr)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("initialization result was not reported")
+			}
+		})
+	}
+}
+
+func TestSessionContextCause(t *testing.T) {
+	var (
+		bodies []map[string]any
+		status = http.StatusOK
+	)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+		return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader([]byte(`{}`))), Header: make(http.Header), Request: req}, nil
+	})}
+	newSession := func() *Session {
+		return &Session{
+			client: &Client{client: httpClient, userInfo: xsts.UserInfo{XUID: "1"}},
+			h:      NopHandler{},
+			cache: SessionDescription{Members: map[string]*MemberDescription{
+				"0": {Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "1"}}},
+				"1": {Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "2"}}},
+			}},
+			reported: map[string]memberState{
+				"0": {xuid: "1", status: MemberStatusInactive},
+				"1": {xuid: "2", status: MemberStatusInactive},
+			},
+			closed: make(chan struct{}),
+		}
+	}
+
+	session := newSession()
+	ctx := session.Context()
+	if err := session.Leave(context.Background()); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+	if got := context.Cause(ctx); !errors.Is(got, ErrSessionLeft) {
+		t.Fatalf("cause after Leave = %v, want %v", got, ErrSessionLeft)
+	}
+
+	session = newSession()
+	status = http.StatusNoContent
+	if err := session.Delete(context.Background()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// The context is created after the Session has been closed.
+	if got := context.Cause(session.Context()); !errors.Is(got, ErrSessionDeleted) {
+		t.Fatalf("cause after Delete = %v, want %v", got, ErrSessionDeleted)
+	}
+	members := bodies[len(bodies)-1]["members"].(map[string]any)
+	for _, label := range []string{"me", "0", "1"} {
+		if member, ok := members[label]; !ok || member != nil {
+			t.Fatalf("Delete did not remove member %q: %v", label, members)
+		}
+	}
+
+	// The directory responds with the remaining session if it has not been deleted.
+	session = newSession()
+	status = http.StatusOK
+	if err := session.Delete(context.Background()); !errors.Is(err, ErrSessionNotDeleted) {
+		t.Fatalf("Delete error = %v, want %v", err, ErrSessionNotDeleted)
+	}
+	if got := context.Cause(session.Context()); !errors.Is(got, ErrSessionLeft) {
+		t.Fatalf("cause after Delete of a remaining session = %v, want %v", got, ErrSessionLeft)
+	}
+
+	session = newSession()
+	session.cacheMu.Lock()
+	delete(session.cache.Members, "0")
+	session.cacheMu.Unlock()
+	session.notify()
+	if got := context.Cause(session.Context()); !errors.Is(got, ErrMemberRemoved) {
+		t.Fatalf("cause after removal = %v, want %v", got, ErrMemberRemoved)
+	}
+}
+
+func TestSessionDeleteLeavesWhenRejected(t *testing.T) {
+	var bodies []map[string]any
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+		status := http.StatusOK
+		if len(bodies) == 1 {
+			// The caller is not permitted to remove other members.
+			status = http.StatusForbidden
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader([]byte(`{}`))), Header: make(http.Header), Request: req}, nil
+	})}
+	session := &Session{
+		client: &Client{client: httpClient, userInfo: xsts.UserInfo{XUID: "1"}},
+		h:      NopHandler{},
+		cache: SessionDescription{Members: map[string]*MemberDescription{
+			"0": {Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "1"}}},
+			"1": {Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "2"}}},
+		}},
+		closed: make(chan struct{}),
+	}
+
+	if err := session.Delete(context.Background()); !errors.Is(err, ErrSessionNotDeleted) {
+		t.Fatalf("Delete error = %v, want %v", err, ErrSessionNotDeleted)
+	}
+	if got := context.Cause(session.Context()); !errors.Is(got, ErrSessionLeft) {
+		t.Fatalf("cause after rejected Delete = %v, want %v", got, ErrSessionLeft)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("requests = %d, want 2", len(bodies))
+	}
+	// The caller leaves the session without removing other members.
+	if members := bodies[1]["members"].(map[string]any); len(members) != 1 || members["me"] != nil {
+		t.Fatalf("leave request members = %v, want only me", members)
+	}
+}
+
+type hostMigrationFunc func(*Session, HostMigration)
+
+func (f hostMigrationFunc) HandleHostMigrated(session *Session, migration HostMigration) {
+	f(session, migration)
+}
+
+func TestSessionHostMigrationClaimsWithETag(t *testing.T) {
+	members := func(host string) SessionDescription {
+		active := func(xuid, token string) *MemberDescription {
+			return &MemberDescription{
+				Constants:   &MemberConstants{System: &MemberConstantsSystem{XUID: xuid}},
+				Properties:  &MemberProperties{System: &MemberPropertiesSystem{Active: true}},
+				DeviceToken: token,
+			}
+		}
+		return SessionDescription{
+			Properties: &SessionProperties{System: &SessionPropertiesSystem{Host: host}},
+			Members: map[string]*MemberDescription{
+				"0":  {Constants: &MemberConstants{System: &MemberConstantsSystem{XUID: "4"}}, DeviceToken: "old"},
+				"3":  active("1", "self"),
+				"12": active("3", "other"),
+			},
+		}
+	}
+
+	var claims atomic.Int32
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		claims.Add(1)
+		if got := req.Header.Get("If-Match"); got != `"etag"` {
+			t.Errorf("If-Match = %q, want %q", got, `"etag"`)
+		}
+		body, err := json.Marshal(members("self"))
+		if err != nil {
+			return nil, err
+		}
+		header := make(http.Header)
+		header.Set("ETag", `"claimed"`)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: header, Request: req}, nil
+	})}
+	session := &Session{
+		client: &Client{client: httpClient, userInfo: xsts.UserInfo{XUID: "1"}},
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		ref:    SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "name"},
+		etag:   `"etag"`,
+		cache:  members("old"),
+		closed: make(chan struct{}),
+	}
+
+	migrations := make(chan HostMigration, 1)
+	session.HandleHostMigration(hostMigrationFunc(func(_ *Session, migration HostMigration) {
+		migrations <- migration
+	}))
+	select {
+	case migration := <-migrations:
+		if migration.Previous != "old" || migration.Current != "self" || migration.Label != "3" || !migration.Self {
+			t.Fatalf("migration = %+v", migration)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("host migration was not reported")
+	}
+	if got := claims.Load(); got != 1 {
+		t.Fatalf("claims = %d, want 1", got)
+	}
+
+	// A client that is not the candidate does not claim the host.
+	session.client.userInfo.XUID = "3"
+	session.cacheMu.Lock()
+	session.cache = members("old")
+	session.cacheMu.Unlock()
+	session.arbitrateHost()
+	if got := claims.Load(); got != 1 {
+		t.Fatalf("claims = %d, want 1", got)
+	}
+}
+
+func TestSessionLocalMembersUseTheirOwnClient(t *testing.T) {
+	var requests []string
+	transport := func(user string) *http.Client {
+		return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			var body struct {
+				Members map[string]*MemberDescription `json:"members"`
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			member, ok := body.Members["me"]
+			switch {
+			case !ok:
+				t.Errorf("request by %s does not change member %q: %v", user, "me", body.Members)
+			case member == nil:
+				requests = append(requests, user+" leave")
+			case member.Constants != nil:
+				requests = append(requests, user+" join "+member.Constants.System.XUID)
+			default:
+				requests = append(requests, user+" properties "+string(member.Properties.Custom))
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(`{}`))), Header: make(http.Header), Request: req}, nil
+		})}
+	}
+	local := &Client{
+		client:       transport("local"),
+		userInfo:     xsts.UserInfo{XUID: "2"},
+		rta:          rta.NewProvider(subscriberFunc(func(context.Context, *rta.Subscription) error { return nil }), nil),
+		subscription: rta.NewSubscription(resourceURI, nil),
+	}
+	local.subscriptionData.Store(&subscriptionData{ConnectionID: uuid.New()})
+	session := &Session{
+		client: &Client{client: transport("owner"), userInfo: xsts.UserInfo{XUID: "1"}},
+		h:      NopHandler{},
+		closed: make(chan struct{}),
+	}
+
+	if err := session.AddLocalMember(context.Background(), local, JoinConfig{}); err != nil {
+		t.Fatalf("AddLocalMember: %v", err)
+	}
+	if err := session.AddLocalMember(context.Background(), session.client, JoinConfig{}); err == nil {
+		t.Fatal("AddLocalMember succeeded for the owner of the session")
+	}
+	if err := session.SetLocalMemberCustomProperties(context.Background(), "2", json.RawMessage(`{"ready":true}`)); err != nil {
+		t.Fatalf("SetLocalMemberCustomProperties: %v", err)
+	}
+	if err := session.SetLocalMemberCustomProperties(context.Background(), "3", json.RawMessage(`{}`)); !errors.Is(err, ErrNotLocalMember) {
+		t.Fatalf("SetLocalMemberCustomProperties error = %v, want %v", err, ErrNotLocalMember)
+	}
+	if err := session.Leave(context.Background()); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+
+	want := []string{"local join 2", `local properties {"ready":true}`, "local leave", "owner leave"}
+	if !slices.Equal(requests, want) {
+		t.Fatalf("requests = %q, want %q", requests, want)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from Appendix A of RFC 7396.
+	for _, tc := range []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{``, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"n":12345678901234567890}`, `{"m":1}`, `{"m":1,"n":12345678901234567890}`},
+	} {
+		got, err := mergePatch(json.RawMessage(tc.target), json.RawMessage(tc.patch))
+		if err != nil {
+			t.Fatalf("mergePatch(%s, %s): %v", tc.target, tc.patch, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("mergePatch(%s, %s) = %s, want %s", tc.target, tc.patch, got, tc.want)
+		}
+	}
+}
+
+func TestSessionPatchCustomPropertiesRetriesOnConflict(t *testing.T) {
+	remote := `{"owner":"a"}`
+	var puts []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		header := make(http.Header)
+		switch req.Method {
+		case http.MethodGet:
+			header.Set("ETag", `"2"`)
+		case http.MethodPut:
+			var body SessionDescription
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			puts = append(puts, req.Header.Get("If-Match")+" "+string(body.Properties.Custom))
+			if req.Header.Get("If-Match") != `"2"` {
+				// Another member has changed the properties since.
+				remote = `{"owner":"a","other":true}`
+				return &http.Response{StatusCode: http.StatusPreconditionFailed, Body: http.NoBody, Header: header, Request: req}, nil
+			}
+			remote = string(body.Properties.Custom)
+			header.Set("ETag", `"3"`)
+		}
+		body := `{"properties":{"custom":` + remote + `}}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(body))), Header: header, Request: req}, nil
+	})}
+	session := &Session{
+		client: &Client{client: httpClient},
+		h:      NopHandler{},
+		etag:   `"1"`,
+		cache:  SessionDescription{Properties: &SessionProperties{Custom: json.RawMessage(`{"owner":"a"}`)}},
+		closed: make(chan struct{}),
+	}
+
+	if err := session.SetCustomProperty(context.Background(), "worldInfo.playerCount", 5); err != nil {
+		t.Fatalf("SetCustomProperty: %v", err)
+	}
+	want := []string{
+		`"1" {"owner":"a","worldInfo":{"playerCount":5}}`,
+		`"2" {"other":true,"owner":"a","worldInfo":{"playerCount":5}}`,
+	}
+	if !slices.Equal(puts, want) {
+		t.Fatalf("PUT requests = %q, want %q", puts, want)
+	}
+	if got := string(session.Properties().Custom); got != remote {
+		t.Fatalf("cached custom properties = %s, want %s", got, remote)
+	}
+}
+
+func TestSessionPatchCustomPropertiesReportsDeletedSession(t *testing.T) {
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// The session has been deleted by the directory instead of being updated.
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Header: make(http.Header), Request: req}, nil
+	})}
+	session := &Session{
+		client: &Client{client: httpClient},
+		h:      NopHandler{},
+		etag:   `"1"`,
+		closed: make(chan struct{}),
+	}
+
+	if err := session.SetCustomProperty(context.Background(), "worldInfo.playerCount", 5); !errors.Is(err, ErrSessionDeleted) {
+		t.Fatalf("SetCustomProperty error = %v, want %v", err, ErrSessionDeleted)
+	}
+	if got := context.Cause(session.Context()); !errors.Is(got, ErrSessionDeleted) {
+		t.Fatalf("cause = %v, want %v", got, ErrSessionDeleted)
+	}
+}
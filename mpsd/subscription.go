@@ -52,6 +52,17 @@ type Handler interface {
 	HandleSessionChange(session *Session)
 }
 
+// MemberHandler may be implemented by a [Handler] registered via [Session.Handle]
+// to receive changes in the status of individual members of the session.
+type MemberHandler interface {
+	Handler
+
+	// HandleMemberChange is called after HandleSessionChange for each member
+	// whose status has changed since the last notification, such as a member
+	// joining, leaving, or a reservation being claimed.
+	HandleMemberChange(session *Session, change MemberChange)
+}
+
 // NopHandler is a no-op implementation of [Handler]. It is used as the default
 // handler for a Session. A custom implementation can be registered at any time
 // via [Session.Handle].
@@ -60,6 +71,24 @@ type NopHandler struct{}
 // HandleSessionChange implements [Handler.HandleSessionChange].
 func (NopHandler) HandleSessionChange(*Session) {}
 
+// HandleMemberChange implements [MemberHandler.HandleMemberChange].
+func (NopHandler) HandleMemberChange(*Session, MemberChange) {}
+
+// MemberChange describes a change in the status of a single member in a
+// multiplayer session between two notifications.
+type MemberChange struct {
+	// Label is the label of the member in the session.
+	Label string
+	// XUID is the XUID of the member, if known.
+	XUID string
+	// Previous is the status of the member at the time of the last notification.
+	// It is [MemberStatusNone] if the member has been added to the session.
+	Previous MemberStatus
+	// Current is the current status of the member. It is [MemberStatusNone] if
+	// the member has been removed from the session.
+	Current MemberStatus
+}
+
 // subscriptionData describes a wire representation of the custom payload
 // included in the RTA subscription.
 type subscriptionData struct {
@@ -111,11 +140,8 @@ func (h *subscriptionHandler) HandleSubscribe(custom json.RawMessage) error {
 					return
 				}
 				session.log.Error("error updating connection ID", "err", err)
-				if closeErr := session.Close(); closeErr != nil {
+				if closeErr := session.abandon(ctx, fmt.Errorf("%w: %w", ErrReconcileFailed, err)); closeErr != nil {
 					session.log.Error("error closing session after connection ID update failure", "err", closeErr)
-					session.closeMu.Lock()
-					session.closeLocked()
-					session.closeMu.Unlock()
 				}
 				return
 			}
@@ -147,7 +173,11 @@ func (h *subscriptionHandler) HandleEvent(custom json.RawMessage) {
 		return
 	}
 
-	refs := make([]SessionReference, 0, len(event.ShoulderTaps))
+	type tappedSession struct {
+		ref SessionReference
+		tap shoulderTap
+	}
+	taps := make([]tappedSession, 0, len(event.ShoulderTaps))
 	for _, tap := range event.ShoulderTaps {
 		ref, err := h.parseReference(tap.Resource)
 		if err != nil {
@@ -155,73 +185,72 @@ func (h *subscriptionHandler) HandleEvent(custom json.RawMessage) {
 				slog.Any("error", err), slog.String("resource", tap.Resource))
 			continue
 		}
-		refs = append(refs, ref)
+		taps = append(taps, tappedSession{ref: ref, tap: tap})
 	}
 
-	for _, session := range h.sessionSnapshot() {
-		if slices.ContainsFunc(refs, func(reference SessionReference) bool {
-			// Shoulder taps may deliver TemplateName and Name in lowercase,
-			// so use Compare for case-insensitive matching.
-			return reference.Equal(session.Reference())
-		}) {
-			go func() {
-				ctx, cancel := context.WithTimeout(session.Context(), time.Second*15)
-				defer cancel()
-
-				if err := h.syncSession(ctx, session); err != nil {
-					h.log.Error("error synchronizing multiplayer session",
-						slog.Any("error", err))
-					return
-				}
-				h.log.Debug("synchronized multiplayer session",
-					slog.Group("session",
-						slog.String("ref", session.Reference().URL().String()),
-					),
-				)
-				session.handler().HandleSessionChange(session)
-			}()
+	sessions := h.sessionSnapshot()
+	var untracked bool
+	for _, t := range taps {
+		// Shoulder taps may deliver TemplateName and Name in lowercase,
+		// so use Equal for case-insensitive matching.
+		i := slices.IndexFunc(sessions, func(session *Session) bool {
+			return t.ref.Equal(session.Reference())
+		})
+		if i == -1 {
+			untracked = true
+			continue
 		}
+		h.handleTap(sessions[i], t.tap)
+	}
+	if untracked {
+		// A shoulder tap for a session not tracked by the Client may indicate
+		// that the caller has been invited to the session.
+		h.triggerInvitePoll()
 	}
 }
 
 func (h *subscriptionHandler) HandleResync() {
 	sessions := h.sessionSnapshot()
+	// Invites may have been missed while the subscription was interrupted.
+	h.triggerInvitePoll()
 	var wg sync.WaitGroup
 	for _, session := range sessions {
 		wg.Go(func() {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
 			defer cancel()
-			if err := h.syncSession(ctx, session); err != nil {
+			// Notifications may have been missed, so the whole session is
+			// retrieved regardless of the cached state.
+			if err := h.syncSession(ctx, session, true); err != nil {
 				h.log.Error("error resyncing multiplayer session", slog.Any("err", err))
 				return
 			}
-			session.handler().HandleSessionChange(session)
+			session.notify()
 		})
 	}
 	wg.Wait()
 }
 
 // syncSession synchronizes session while ordered against subscription
-// reconciliation, but returns before user callbacks are invoked.
-func (h *subscriptionHandler) syncSession(ctx context.Context, session *Session) error {
+// reconciliation, but returns before user callbacks are invoked. If full
+// is true, the whole session is retrieved instead of performing a conditional GET.
+func (h *subscriptionHandler) syncSession(ctx context.Context, session *Session, full bool) error {
 	h.reconcileMu.RLock()
 	defer h.reconcileMu.RUnlock()
-	return session.Sync(ctx)
+	return session.fetch(ctx, !full)
 }
 
 func (h *subscriptionHandler) HandleError(err error) {
+	cause := fmt.Errorf("%w: %w", ErrSubscriptionLost, err)
 	for _, session := range h.sessionSnapshot() {
-		// TODO: Cancel the background context of the session.
 		session.log.Error("subscription lost", "err", err)
 		go func() {
 			h.reconcileMu.RLock()
 			defer h.reconcileMu.RUnlock()
 
-			if closeErr := session.Close(); closeErr != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+			defer cancel()
+			if closeErr := session.abandon(ctx, cause); closeErr != nil {
 				session.log.Error("error closing session after subscription loss", "err", closeErr)
-				session.closeMu.Lock()
-				session.closeLocked()
-				session.closeMu.Unlock()
 			}
 		}()
 	}
@@ -0,0 +1,153 @@
+package mpsd
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tapState tracks the shoulder taps received for a Session over RTA.
+//
+// Shoulder taps received while the session is being synchronized are coalesced
+// so that a burst of taps results in a single GET request. Taps referencing a
+// version that is already cached are skipped.
+type tapState struct {
+	mu sync.Mutex
+	// latest is the most recent shoulder tap received for the session.
+	latest shoulderTap
+	// dirty reports whether a shoulder tap has been received since the
+	// last synchronization started.
+	dirty bool
+	// full reports whether the next synchronization must retrieve the whole
+	// session instead of performing a conditional GET.
+	full bool
+	// syncing reports whether a goroutine is synchronizing the session.
+	syncing bool
+}
+
+// version returns the branch and change number of the cached session state.
+func (s *Session) version() (branch uuid.UUID, changeNumber uint64) {
+	s.cacheMu.RLock()
+	defer s.cacheMu.RUnlock()
+	return s.cache.Branch, s.cache.ChangeNumber
+}
+
+// observe records the shoulder tap for the session at the cached version. It reports
+// whether the caller should start synchronizing the session using [tapState.next].
+//
+// A tap is ignored if the cached session is already at or past the referenced change.
+// A tap on a different branch, or a tap that skips one or more change numbers, forces
+// the next synchronization to retrieve the whole session.
+func (t *tapState) observe(tap shoulderTap, branch uuid.UUID, changeNumber uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tap.Branch == branch && tap.ChangeNumber <= changeNumber {
+		return false
+	}
+	if branch != uuid.Nil && (tap.Branch != branch || tap.ChangeNumber > changeNumber+1) {
+		t.full = true
+	}
+	if tap.Branch != t.latest.Branch || tap.ChangeNumber > t.latest.ChangeNumber {
+		t.latest = tap
+	}
+	t.dirty = true
+	if t.syncing {
+		return false
+	}
+	t.syncing = true
+	return true
+}
+
+// next reports whether the session at the given version needs to be synchronized
+// again because of taps received since the last synchronization started, and whether
+// the synchronization must retrieve the whole session. If ok is false, the caller must
+// stop synchronizing.
+func (t *tapState) next(branch uuid.UUID, changeNumber uint64) (full, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dirty && t.latest.Branch == branch && t.latest.ChangeNumber <= changeNumber {
+		// Taps received during the last synchronization are already reflected
+		// in the cached session state.
+		t.dirty, t.full = false, false
+	}
+	if !t.dirty {
+		t.syncing = false
+		return false, false
+	}
+	full = t.full
+	t.dirty, t.full = false, false
+	return full, true
+}
+
+// behind reports whether the session at the given version is still behind the latest
+// shoulder tap after a synchronization. If so, and the synchronization was conditional,
+// another synchronization retrieving the whole session is scheduled.
+func (t *tapState) behind(full bool, branch uuid.UUID, changeNumber uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.latest.Branch == branch && t.latest.ChangeNumber <= changeNumber {
+		return false
+	}
+	if !full {
+		t.dirty, t.full = true, true
+	}
+	return true
+}
+
+// stop resets the synchronization state so that the next shoulder tap starts
+// synchronizing the session again.
+func (t *tapState) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.syncing, t.dirty, t.full = false, false, false
+}
+
+// handleTap records the shoulder tap received for the session and synchronizes the
+// session in the background unless a synchronization is already in progress.
+func (h *subscriptionHandler) handleTap(session *Session, tap shoulderTap) {
+	branch, changeNumber := session.version()
+	if !session.taps.observe(tap, branch, changeNumber) {
+		return
+	}
+	go h.drainTaps(session)
+}
+
+// drainTaps synchronizes the session until no further shoulder taps are pending,
+// notifying the Handler of the session after each synchronization.
+func (h *subscriptionHandler) drainTaps(session *Session) {
+	for {
+		full, ok := session.taps.next(session.version())
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(session.Context(), time.Second*15)
+		err := h.syncSession(ctx, session, full)
+		cancel()
+		if err != nil {
+			session.taps.stop()
+			h.log.Error("error synchronizing multiplayer session",
+				slog.Any("error", err))
+			return
+		}
+		branch, changeNumber := session.version()
+		if session.taps.behind(full, branch, changeNumber) && full {
+			h.log.Debug("multiplayer session is behind shoulder tap after full synchronization",
+				slog.String("ref", session.Reference().URL().String()),
+				slog.Uint64("changeNumber", changeNumber))
+		}
+		h.log.Debug("synchronized multiplayer session",
+			slog.Group("session",
+				slog.String("ref", session.Reference().URL().String()),
+				slog.Uint64("changeNumber", changeNumber),
+			),
+		)
+		session.notify()
+	}
+}
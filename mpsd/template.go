@@ -0,0 +1,242 @@
+package mpsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTemplateMismatch is returned by [Client.Publish] and [Client.Join] when validation
+	// is enabled and the configuration conflicts with the constants of the session template.
+	ErrTemplateMismatch = errors.New("mpsd: configuration does not match session template")
+	// ErrSessionClosed is returned by [Client.Join] when validation is enabled and
+	// the multiplayer session is closed to new members.
+	ErrSessionClosed = errors.New("mpsd: session is closed")
+)
+
+// SessionTemplate describes a session template configured for a service configuration.
+// A session template defines the constants applied to each multiplayer session created
+// using the template.
+type SessionTemplate struct {
+	// Name is the name of the session template. It corresponds to
+	// [SessionReference.TemplateName].
+	Name string `json:"name,omitempty"`
+
+	// ServiceConfigID is the service configuration ID (SCID) that owns the session template.
+	ServiceConfigID uuid.UUID `json:"scid,omitzero"`
+
+	// ContractVersion is the MPSD contract version of the session template.
+	ContractVersion uint32 `json:"contractVersion"`
+
+	// Constants contains the constants applied to multiplayer sessions created using
+	// the template. It is only present in the result of [Client.SessionTemplate].
+	Constants *SessionConstants `json:"constants,omitempty"`
+}
+
+// SessionTemplates returns the session templates configured for the service configuration
+// identified by the SCID. The returned templates only include the name and the contract
+// version of each template; use [Client.SessionTemplate] to retrieve its constants.
+func (c *Client) SessionTemplates(ctx context.Context, scid uuid.UUID, opts ...internal.RequestOption) ([]SessionTemplate, error) {
+	var result struct {
+		Templates []SessionTemplate `json:"results"`
+	}
+	if err := internal.Do(ctx, c.client, http.MethodGet, templateURL(scid, "").String(), nil, &result, append(opts,
+		internal.ContractVersion(contractVersion),
+	)); err != nil {
+		return nil, err
+	}
+	for i := range result.Templates {
+		if result.Templates[i].ServiceConfigID == uuid.Nil {
+			result.Templates[i].ServiceConfigID = scid
+		}
+	}
+	return result.Templates, nil
+}
+
+// SessionTemplate returns the session template identified by the name in the service
+// configuration identified by the SCID, including the constants applied to multiplayer
+// sessions created using the template.
+func (c *Client) SessionTemplate(ctx context.Context, scid uuid.UUID, name string, opts ...internal.RequestOption) (*SessionTemplate, error) {
+	template := &SessionTemplate{
+		Name:            name,
+		ServiceConfigID: scid,
+	}
+	if err := internal.Do(ctx, c.client, http.MethodGet, templateURL(scid, name).String(), nil, template, append(opts,
+		internal.ContractVersion(contractVersion),
+	)); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// templateURL returns the URL locating to the session templates of the service configuration.
+// If name is non-empty, the URL locates to the session template identified by the name.
+func templateURL(scid uuid.UUID, name string) *url.URL {
+	u := endpoint.JoinPath("serviceconfigs", scid.String(), "sessiontemplates")
+	if name != "" {
+		u = u.JoinPath(name)
+	}
+	return u
+}
+
+// validatePublish reports an error if the PublishConfig cannot be used to publish a
+// multiplayer session using the template. The restrictions must already have defaults applied.
+func validatePublish(template *SessionTemplate, config PublishConfig) error {
+	if err := validateRestriction(config.JoinRestriction); err != nil {
+		return fmt.Errorf("join restriction: %w", err)
+	}
+	if err := validateRestriction(config.ReadRestriction); err != nil {
+		return fmt.Errorf("read restriction: %w", err)
+	}
+	if err := validateContractVersion(template); err != nil {
+		return err
+	}
+	if template.Constants != nil {
+		// Constants defined by the template cannot be overridden by the session.
+		if err := validateConstantsOverride(template.Constants.Custom, config.CustomConstants); err != nil {
+			return fmt.Errorf("%w: template %q: custom constants: %w", ErrTemplateMismatch, template.Name, err)
+		}
+		if template.Constants.System != nil && config.SystemConstants != nil {
+			templateSystem, err := json.Marshal(template.Constants.System)
+			if err != nil {
+				return fmt.Errorf("encode template system constants: %w", err)
+			}
+			system, err := json.Marshal(config.SystemConstants)
+			if err != nil {
+				return fmt.Errorf("encode system constants: %w", err)
+			}
+			if err := validateConstantsOverride(templateSystem, system); err != nil {
+				return fmt.Errorf("%w: template %q: system constants: %w", ErrTemplateMismatch, template.Name, err)
+			}
+		}
+	}
+	if err := validateSystemConstants(template, config.SystemConstants); err != nil {
+		return fmt.Errorf("%w: template %q: system constants: %w", ErrTemplateMismatch, template.Name, err)
+	}
+	return nil
+}
+
+// maxMembersCount is the maximum number of members allowed in a multiplayer
+// session that does not have the large capability.
+const maxMembersCount = 100
+
+// validateSystemConstants reports an error if the system constants, combined with the
+// system constants defined by the template, describe a session the directory rejects.
+// Fields defined by the template take precedence over those defined by the constants.
+func validateSystemConstants(template *SessionTemplate, constants *SessionConstantsSystem) error {
+	var effective SessionConstantsSystem
+	if constants != nil {
+		effective = *constants
+	}
+	if template.Constants != nil && template.Constants.System != nil {
+		system := template.Constants.System
+		if system.MaxMembersCount != 0 {
+			effective.MaxMembersCount = system.MaxMembersCount
+		}
+		if system.Visibility != "" {
+			effective.Visibility = system.Visibility
+		}
+		if system.Capabilities != nil {
+			effective.Capabilities = system.Capabilities
+		}
+	}
+
+	switch effective.Visibility {
+	case "", SessionVisibilityPrivate, SessionVisibilityVisible, SessionVisibilityOpen:
+	default:
+		return fmt.Errorf("unknown visibility %q", effective.Visibility)
+	}
+	var capabilities SessionCapabilities
+	if effective.Capabilities != nil {
+		capabilities = *effective.Capabilities
+	}
+	if effective.MaxMembersCount > maxMembersCount && !capabilities.Large {
+		return fmt.Errorf("max members count %d exceeds %d without the large capability", effective.MaxMembersCount, maxMembersCount)
+	}
+	if capabilities.Large && (capabilities.Connectivity || capabilities.ConnectionRequiredForActiveMembers) {
+		return errors.New("the large capability does not support connectivity")
+	}
+	return nil
+}
+
+// validateConstantsOverride reports an error if the constants define a field that is
+// already defined by the template constants. Constants that are not a JSON object are ignored.
+func validateConstantsOverride(template, constants json.RawMessage) error {
+	if len(template) == 0 || len(constants) == 0 {
+		return nil
+	}
+	var templateFields, fields map[string]json.RawMessage
+	if json.Unmarshal(template, &templateFields) != nil || json.Unmarshal(constants, &fields) != nil {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if _, ok := templateFields[name]; ok {
+			return fmt.Errorf("field %q is defined by the template", name)
+		}
+	}
+	return nil
+}
+
+// validateJoin retrieves the handle identified by the ID and the session template of the
+// referenced multiplayer session, and reports an error if the session cannot be joined.
+func (c *Client) validateJoin(ctx context.Context, handleID uuid.UUID, opts []internal.RequestOption) error {
+	var handle ActivityHandle
+	requestURL := endpoint.JoinPath("handles", handleID.String())
+	requestURL.RawQuery = "include=relatedInfo"
+	if err := internal.Do(ctx, c.client, http.MethodGet, requestURL.String(), nil, &handle, append(opts,
+		internal.ContractVersion(contractVersion),
+	)); err != nil {
+		return fmt.Errorf("mpsd: retrieve handle %s: %w", handleID, err)
+	}
+	ref := handle.SessionReference
+	template, err := c.SessionTemplate(ctx, ref.ServiceConfigID, ref.TemplateName, opts...)
+	if err != nil {
+		return fmt.Errorf("mpsd: retrieve session template %q: %w", ref.TemplateName, err)
+	}
+	return validateJoin(template, handle)
+}
+
+// validateJoin reports an error if the multiplayer session referenced by the handle
+// cannot be joined, according to the related info of the handle and the template.
+func validateJoin(template *SessionTemplate, handle ActivityHandle) error {
+	if err := validateContractVersion(template); err != nil {
+		return err
+	}
+	info := handle.RelatedInfo
+	if info == nil {
+		return nil
+	}
+	if info.Closed {
+		return fmt.Errorf("%w: %s", ErrSessionClosed, handle.SessionReference.URL())
+	}
+	limit := info.MaxMembersCount
+	if limit == 0 && template.Constants != nil && template.Constants.System != nil {
+		limit = template.Constants.System.MaxMembersCount
+	}
+	if limit != 0 && info.MembersCount >= limit {
+		return fmt.Errorf("%w: session %s is full with %d of %d members", ErrTooManyMembers, handle.SessionReference.URL(), info.MembersCount, limit)
+	}
+	return nil
+}
+
+// validateContractVersion reports an error wrapping [ErrTemplateMismatch] if the
+// template uses a contract version different from the one used by this package.
+func validateContractVersion(template *SessionTemplate) error {
+	if template.ContractVersion == 0 {
+		return nil
+	}
+	if v := strconv.FormatUint(uint64(template.ContractVersion), 10); v != contractVersion {
+		return fmt.Errorf("%w: template %q uses contract version %s, want %s", ErrTemplateMismatch, template.Name, v, contractVersion)
+	}
+	return nil
+}
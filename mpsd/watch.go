@@ -0,0 +1,101 @@
+package mpsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ErrReadOnly is returned when a mutation is attempted on a read-only Session
+// returned by [Client.Watch].
+var ErrReadOnly = errors.New("mpsd: session is read-only")
+
+// WatchConfig describes a configuration for observing a multiplayer session
+// using [Client.Watch].
+type WatchConfig struct {
+	// PollInterval is the interval at which the session is polled for changes.
+	// If zero, a default of 5 seconds is used.
+	PollInterval time.Duration
+
+	// Logger is the logger used for reporting errors while polling the session.
+	// If nil, the logger of the Client is used.
+	Logger *slog.Logger
+}
+
+// Watch observes the multiplayer session identified by the reference without joining
+// it. The caller must be allowed to read the session, as determined by the read
+// restriction of the session.
+//
+// Change notifications over RTA (Real-Time Activity) are only delivered to active
+// members of a session, so the returned Session is kept up-to-date by polling the
+// session using conditional GET requests at [WatchConfig.PollInterval]. The Handler,
+// which may be nil, is notified whenever a change is observed, in the same way as
+// for sessions returned from [Client.Publish] or [Client.Join].
+//
+// The returned Session is read-only. Accessors such as [Session.Members] and
+// [Session.Properties] behave as usual, but mutations fail with [ErrReadOnly].
+// Closing the Session stops polling without making any request. The Session is
+// closed automatically when the remote session is no longer found.
+func (c *Client) Watch(ctx context.Context, ref SessionReference, h Handler, config WatchConfig) (*Session, error) {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second * 5
+	}
+
+	s := c.newSession(ref, SessionDescription{}, "")
+	s.readOnly = true
+	if config.Logger != nil {
+		s.log = config.Logger
+	}
+	if err := s.Sync(ctx); err != nil {
+		return nil, fmt.Errorf("mpsd: sync session: %w", err)
+	}
+	s.reported = memberStates(s.cache)
+	s.Handle(h)
+
+	go s.poll(config.PollInterval)
+	return s, nil
+}
+
+// poll synchronizes a read-only Session at the interval until it is closed,
+// notifying the Handler of the Session whenever a change has been observed.
+func (s *Session) poll(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-t.C:
+		}
+
+		s.cacheMu.RLock()
+		etag, branch, changeNumber := s.etag, s.cache.Branch, s.cache.ChangeNumber
+		s.cacheMu.RUnlock()
+
+		ctx, cancel := context.WithTimeout(s.Context(), time.Second*15)
+		err := s.Sync(ctx)
+		cancel()
+		if err != nil {
+			var responseErr *ResponseError
+			if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+				s.log.Debug("watched session no longer exists")
+				s.markDeleted()
+				return
+			}
+			if !errors.Is(err, context.Canceled) {
+				s.log.Error("error polling multiplayer session", "err", err)
+			}
+			continue
+		}
+
+		s.cacheMu.RLock()
+		changed := s.etag != etag || s.cache.Branch != branch || s.cache.ChangeNumber != changeNumber
+		s.cacheMu.RUnlock()
+		if changed {
+			s.notify()
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+// New returns a new Client with the provided components.
+func New(client *http.Client, userInfo xsts.UserInfo) *Client {
+	return &Client{
+		client:   client,
+		userInfo: userInfo,
+	}
+}
+
+// Client implements API client for Xbox Live Profile API.
+type Client struct {
+	client   *http.Client
+	userInfo xsts.UserInfo
+}
+
+// Current returns the settings of the caller's profile. If settings is empty,
+// [DefaultSettings] are returned.
+func (c *Client) Current(ctx context.Context, settings []Setting, opts ...internal.RequestOption) (*Profile, error) {
+	if len(settings) == 0 {
+		settings = defaultSettings
+	}
+	requestURL := endpoint.JoinPath("users", "me", "profile", "settings")
+	requestURL.RawQuery = url.Values{
+		"settings": []string{joinSettings(settings)},
+	}.Encode()
+
+	var result batchResponse
+	if err := internal.Do(ctx, c.client, http.MethodGet, requestURL.String(), nil, &result, append(opts,
+		contractVersion,
+		internal.DefaultLanguage,
+	)); err != nil {
+		return nil, err
+	}
+	if len(result.Users) == 0 {
+		return nil, errors.New("xsapi/profile: no profile found")
+	}
+	return &result.Users[0], nil
+}
+
+// ProfileByXUID returns the settings of the profile of the user identified by the XUID.
+// If settings is empty, [DefaultSettings] are returned.
+func (c *Client) ProfileByXUID(ctx context.Context, xuid string, settings []Setting, opts ...internal.RequestOption) (*Profile, error) {
+	profiles, err := c.Batch(ctx, []string{xuid}, settings, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, errors.New("xsapi/profile: no profile found")
+	}
+	return &profiles[0], nil
+}
+
+// Batch returns the settings of the profiles of the users identified by the XUIDs. If settings
+// is empty, [DefaultSettings] are returned. The XUIDs are split into chunks of up to 100 users
+// as required by the service, and the profiles are returned in the order they are returned
+// by the service. Users whose profile could not be found are not included in the result.
+func (c *Client) Batch(ctx context.Context, xuids []string, settings []Setting, opts ...internal.RequestOption) ([]Profile, error) {
+	if len(settings) == 0 {
+		settings = defaultSettings
+	}
+	requestURL := endpoint.JoinPath("users", "batch", "profile", "settings").String()
+
+	profiles := make([]Profile, 0, len(xuids))
+	for chunk := range slices.Chunk(xuids, batchLimit) {
+		var result batchResponse
+		if err := internal.Do(ctx, c.client, http.MethodPost, requestURL, batchRequest{
+			XUIDs:    chunk,
+			Settings: settings,
+		}, &result, append(opts,
+			contractVersion,
+			internal.DefaultLanguage,
+		)); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, result.Users...)
+	}
+	return profiles, nil
+}
+
+// Update updates the setting of the caller's profile to the value. Only the settings
+// that can be edited by the user, such as [SettingBio] and [SettingLocation], may be updated.
+func (c *Client) Update(ctx context.Context, setting Setting, value string, opts ...internal.RequestOption) error {
+	requestURL := endpoint.JoinPath("users", "me", "profile", "settings").String()
+	req, err := internal.WithJSONBody(ctx, http.MethodPost, requestURL, updateRequest{
+		Setting: SettingValue{
+			ID:    setting,
+			Value: value,
+		},
+	}, append(opts,
+		contractVersion,
+		internal.RequestHeader("Content-Type", "application/json"),
+		internal.DefaultLanguage,
+	))
+	if err != nil {
+		return fmt.Errorf("make request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	default:
+		return internal.UnexpectedStatusCode(resp)
+	}
+}
+
+// SetBio updates the bio of the caller's profile. It is equivalent to calling
+// [Client.Update] with [SettingBio].
+func (c *Client) SetBio(ctx context.Context, bio string, opts ...internal.RequestOption) error {
+	return c.Update(ctx, SettingBio, bio, opts...)
+}
+
+// SetLocation updates the location of the caller's profile. It is equivalent to
+// calling [Client.Update] with [SettingLocation].
+func (c *Client) SetLocation(ctx context.Context, location string, opts ...internal.RequestOption) error {
+	return c.Update(ctx, SettingLocation, location, opts...)
+}
+
+// joinSettings joins the settings into a comma-separated list.
+func joinSettings(settings []Setting) string {
+	s := make([]string, len(settings))
+	for i, setting := range settings {
+		s[i] = string(setting)
+	}
+	return strings.Join(s, ",")
+}
+
+// batchLimit is the maximum number of XUIDs included in a single batch request.
+const batchLimit = 100
+
+type (
+	// batchRequest is the wire representation of a request body used to
+	// retrieve the profile settings of multiple users.
+	batchRequest struct {
+		// XUIDs lists the XUIDs of the users to be retrieved.
+		XUIDs []string `json:"userIds"`
+		// Settings lists the settings to be retrieved for each user.
+		Settings []Setting `json:"settings"`
+	}
+
+	// batchResponse is the response body returned by the Profile API,
+	// containing the profiles of the users matching the query.
+	batchResponse struct {
+		// Users lists the profiles of the users.
+		Users []Profile `json:"profileUsers"`
+	}
+
+	// updateRequest is the wire representation of a request body used to
+	// update a setting of the caller's profile.
+	updateRequest struct {
+		// Setting is the setting to be updated.
+		Setting SettingValue `json:"userSetting"`
+	}
+)
+
+var (
+	// endpoint is the base URL for the Xbox Live Profile API.
+	//
+	// Requests sent to this endpoint must include the 'X-Xbl-Contract-Version'
+	// header set to '3'. The contractVersion request option can be used
+	// for this purpose.
+	endpoint = &url.URL{
+		Scheme: "https",
+		Host:   "profile.xboxlive.com",
+	}
+
+	// contractVersion is an [internal.RequestOption] that sets the
+	// 'X-Xbl-Contract-Version' header to '3' for requests made to the
+	// endpoint.
+	contractVersion = internal.ContractVersion("3")
+)
@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBatchSplitsIntoChunks(t *testing.T) {
+	xuids := make([]string, batchLimit+1)
+	for i := range xuids {
+		xuids[i] = strconv.Itoa(i)
+	}
+	var sizes []int
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body batchRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if len(body.Settings) != 1 || body.Settings[0] != SettingGamertag {
+			t.Errorf("settings = %v, want [%s]", body.Settings, SettingGamertag)
+		}
+		sizes = append(sizes, len(body.XUIDs))
+		users := make([]string, len(body.XUIDs))
+		for i, xuid := range body.XUIDs {
+			users[i] = fmt.Sprintf(`{"id":%q,"settings":[{"id":"Gamertag","value":"user%s"}]}`, xuid, xuid)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"profileUsers":[` + strings.Join(users, ",") + `]}`)),
+			Request:    req,
+		}, nil
+	})}, xsts.UserInfo{})
+
+	profiles, err := client.Batch(context.Background(), xuids, []Setting{SettingGamertag})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if len(sizes) != 2 || sizes[0] != batchLimit || sizes[1] != 1 {
+		t.Fatalf("chunk sizes = %v, want [%d 1]", sizes, batchLimit)
+	}
+	if len(profiles) != len(xuids) {
+		t.Fatalf("got %d profiles, want %d", len(profiles), len(xuids))
+	}
+	if gamertag, ok := profiles[batchLimit].Setting(SettingGamertag); !ok || gamertag != "user100" {
+		t.Fatalf("Setting(Gamertag) = %q, %v", gamertag, ok)
+	}
+}
@@ -0,0 +1,96 @@
+package profile
+
+import "slices"
+
+// Profile holds the settings of the profile of a single user.
+type Profile struct {
+	// XUID is the Xbox User ID (XUID) of the user.
+	XUID string `json:"id"`
+	// HostID is the XUID of the user hosting the profile. It is usually
+	// the same as XUID.
+	HostID string `json:"hostId"`
+	// Settings lists the settings of the profile requested by the caller.
+	Settings []SettingValue `json:"settings"`
+	// Sponsored reports whether the user is a sponsored user, such as a guest
+	// signed in on the same device.
+	Sponsored bool `json:"isSponsoredUser"`
+}
+
+// Setting returns the value of the setting in the profile. The boolean result
+// reports whether the setting is present in the profile.
+func (p Profile) Setting(setting Setting) (string, bool) {
+	for _, s := range p.Settings {
+		if s.ID == setting {
+			return s.Value, true
+		}
+	}
+	return "", false
+}
+
+// SettingValue is the value of a single setting in a profile.
+type SettingValue struct {
+	// ID identifies the setting.
+	ID Setting `json:"id"`
+	// Value is the value of the setting in the string form.
+	Value string `json:"value"`
+}
+
+// Setting identifies a setting in a profile.
+type Setting string
+
+const (
+	// SettingGameDisplayName is the name of the user displayed in titles.
+	SettingGameDisplayName Setting = "GameDisplayName"
+	// SettingGameDisplayPicRaw is the URL of the gamerpic of the user.
+	SettingGameDisplayPicRaw Setting = "GameDisplayPicRaw"
+	// SettingGamerscore is the gamerscore of the user as a decimal integer.
+	SettingGamerscore Setting = "Gamerscore"
+	// SettingGamertag is the classic gamertag of the user.
+	SettingGamertag Setting = "Gamertag"
+	// SettingModernGamertag is the modern gamertag of the user, without the suffix.
+	SettingModernGamertag Setting = "ModernGamertag"
+	// SettingModernGamertagSuffix is the suffix of the modern gamertag of the user.
+	SettingModernGamertagSuffix Setting = "ModernGamertagSuffix"
+	// SettingUniqueModernGamertag is the modern gamertag of the user including the suffix.
+	SettingUniqueModernGamertag Setting = "UniqueModernGamertag"
+	// SettingAccountTier is the account tier of the user, such as "Gold" or "Silver".
+	SettingAccountTier Setting = "AccountTier"
+	// SettingTenureLevel is the number of years the user has been a member of Xbox Live.
+	SettingTenureLevel Setting = "TenureLevel"
+	// SettingXboxOneRep is the reputation of the user, such as "GoodPlayer".
+	SettingXboxOneRep Setting = "XboxOneRep"
+	// SettingPreferredColor is the URL of the preferred color of the user.
+	SettingPreferredColor Setting = "PreferredColor"
+	// SettingRealName is the real name of the user. Its visibility is subject
+	// to the privacy settings of the user.
+	SettingRealName Setting = "RealName"
+	// SettingBio is the bio of the user. It can be updated by the user using
+	// [Client.SetBio].
+	SettingBio Setting = "Bio"
+	// SettingLocation is the location of the user. It can be updated by the user
+	// using [Client.SetLocation].
+	SettingLocation Setting = "Location"
+	// SettingWatermarks lists the watermarks of the user, separated by a pipe.
+	SettingWatermarks Setting = "Watermarks"
+)
+
+// DefaultSettings returns the list of settings returned when no settings are
+// specified by the caller. The returned slice is a copy and may be modified.
+func DefaultSettings() []Setting {
+	return slices.Clone(defaultSettings)
+}
+
+// defaultSettings is the list of settings returned by [DefaultSettings].
+var defaultSettings = []Setting{
+	SettingGameDisplayName,
+	SettingGameDisplayPicRaw,
+	SettingGamerscore,
+	SettingGamertag,
+	SettingModernGamertag,
+	SettingModernGamertagSuffix,
+	SettingUniqueModernGamertag,
+	SettingAccountTier,
+	SettingXboxOneRep,
+	SettingBio,
+	SettingLocation,
+}
@@ -0,0 +1,145 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+	"github.com/df-mc/go-xsapi/v2/mpsd"
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+// New returns a new Client with the provided components.
+func New(client *http.Client, userInfo xsts.UserInfo) *Client {
+	return &Client{
+		client:   client,
+		userInfo: userInfo,
+	}
+}
+
+// Client implements API client for Xbox Live Reputation API.
+type Client struct {
+	client   *http.Client
+	userInfo xsts.UserInfo
+}
+
+// Feedback describes feedback submitted about a user with [Client.Report].
+type Feedback struct {
+	// Type is the kind of feedback. It must be set.
+	Type FeedbackType
+	// Session is the reference to the multiplayer session in which the reported
+	// behavior occurred, which is used by enforcement as the evidence context.
+	// It may be left empty if the feedback is not related to a multiplayer session.
+	Session mpsd.SessionReference
+	// Reason is an optional, user-supplied text describing the feedback.
+	Reason string
+	// EvidenceID is the optional ID of a resource supporting the feedback,
+	// such as a game clip or a screenshot.
+	EvidenceID string
+}
+
+// Report submits the feedback about the user identified by the XUID.
+//
+// The returned error may be matched against [ErrDuplicateReport] if the caller has
+// already submitted the same feedback about the user, or [ErrThrottled] if the caller
+// has submitted too much feedback recently, using [errors.Is].
+func (c *Client) Report(ctx context.Context, xuid string, feedback Feedback, opts ...internal.RequestOption) error {
+	if feedback.Type == "" {
+		return errors.New("xsapi/reputation: feedback type must be set")
+	}
+	if xuid == c.userInfo.XUID {
+		return errors.New("xsapi/reputation: cannot submit feedback about the caller")
+	}
+	request := feedbackRequest{
+		FeedbackType: feedback.Type,
+		TextReason:   feedback.Reason,
+		EvidenceID:   feedback.EvidenceID,
+	}
+	if feedback.Session != (mpsd.SessionReference{}) {
+		request.SessionReference = &feedback.Session
+	}
+
+	requestURL := endpoint.JoinPath("users", "xuid("+xuid+")", "feedback").String()
+	req, err := internal.WithJSONBody(ctx, http.MethodPost, requestURL, request, append(opts,
+		contractVersion,
+		internal.RequestHeader("Content-Type", "application/json"),
+		internal.DefaultLanguage,
+	))
+	if err != nil {
+		return fmt.Errorf("make request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	default:
+		return responseError(resp)
+	}
+}
+
+// Standing returns the reputation of the caller.
+func (c *Client) Standing(ctx context.Context, opts ...internal.RequestOption) (*Standing, error) {
+	requestURL := endpoint.JoinPath("users", "xuid("+c.userInfo.XUID+")", "reputation").String()
+	req, err := internal.NewRequest(ctx, http.MethodGet, requestURL, nil, append(opts,
+		contractVersion,
+		internal.DefaultLanguage,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("make request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+	var standing *Standing
+	if err := json.NewDecoder(resp.Body).Decode(&standing); err != nil {
+		return nil, fmt.Errorf("decode response body: %w", err)
+	}
+	if standing == nil {
+		return nil, errors.New("xsapi/reputation: invalid reputation response")
+	}
+	return standing, nil
+}
+
+// feedbackRequest is the wire representation of a request body used to
+// submit feedback about a user.
+type feedbackRequest struct {
+	// SessionReference is the reference to the multiplayer session
+	// used as the evidence context, if any.
+	SessionReference *mpsd.SessionReference `json:"sessionRef,omitempty"`
+	// FeedbackType is the kind of feedback.
+	FeedbackType FeedbackType `json:"feedbackType"`
+	// TextReason is the user-supplied text describing the feedback.
+	TextReason string `json:"textReason,omitempty"`
+	// EvidenceID is the ID of a resource supporting the feedback.
+	EvidenceID string `json:"evidenceId,omitempty"`
+}
+
+var (
+	// endpoint is the base URL for the Xbox Live Reputation API.
+	//
+	// Requests sent to this endpoint must include the 'X-Xbl-Contract-Version'
+	// header set to '101'. The contractVersion request option can be used
+	// for this purpose.
+	endpoint = &url.URL{
+		Scheme: "https",
+		Host:   "reputation.xboxlive.com",
+	}
+
+	// contractVersion is an [internal.RequestOption] that sets the
+	// 'X-Xbl-Contract-Version' header to '101' for requests made to the
+	// endpoint.
+	contractVersion = internal.ContractVersion("101")
+)
@@ -0,0 +1,86 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/mpsd"
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+	"github.com/google/uuid"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestReportIncludesSessionAndReturnsTypedErrors(t *testing.T) {
+	ref := mpsd.SessionReference{ServiceConfigID: uuid.New(), TemplateName: "template", Name: "SESSION"}
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			SessionReference mpsd.SessionReference `json:"sessionRef"`
+			FeedbackType     FeedbackType          `json:"feedbackType"`
+			TextReason       string                `json:"textReason"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if body.SessionReference != ref || body.FeedbackType != FeedbackFairPlayCheater || body.TextReason != "aimbot" {
+			return nil, fmt.Errorf("unexpected request body %+v", body)
+		}
+		resp := &http.Response{
+			Header:  make(http.Header),
+			Request: req,
+			Body:    io.NopCloser(strings.NewReader("")),
+		}
+		switch req.URL.Path {
+		case "/users/xuid(2)/feedback":
+			resp.StatusCode = http.StatusAccepted
+		case "/users/xuid(3)/feedback":
+			resp.StatusCode = http.StatusConflict
+		case "/users/xuid(4)/feedback":
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Header.Set("Retry-After", "30")
+		case "/users/xuid(5)/feedback":
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		default:
+			return nil, fmt.Errorf("unexpected path %q", req.URL.Path)
+		}
+		return resp, nil
+	})}, xsts.UserInfo{XUID: "1"})
+
+	feedback := Feedback{Type: FeedbackFairPlayCheater, Session: ref, Reason: "aimbot"}
+	if err := client.Report(context.Background(), "2", feedback); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if err := client.Report(context.Background(), "3", feedback); !errors.Is(err, ErrDuplicateReport) {
+		t.Fatalf("Report error = %v, want %v", err, ErrDuplicateReport)
+	}
+	var responseErr *ResponseError
+	if err := client.Report(context.Background(), "4", feedback); !errors.Is(err, ErrThrottled) || !errors.As(err, &responseErr) || responseErr.RetryAfter != 30*time.Second {
+		t.Fatalf("Report error = %v, want %v with retry after", err, ErrThrottled)
+	}
+	if err := client.Report(context.Background(), "5", feedback); !errors.As(err, &responseErr) || responseErr.RetryAfter <= 0 || responseErr.RetryAfter > time.Minute {
+		t.Fatalf("Report error = %v, want retry after from HTTP date", err)
+	}
+	if err := client.Report(context.Background(), "1", feedback); err == nil {
+		t.Fatal("Report about the caller returned nil error")
+	}
+	// A 409 Conflict response to a request other than feedback is not a duplicate report.
+	if err := (&ResponseError{
+		Method:     http.MethodGet,
+		URL:        endpoint.JoinPath("users", "xuid(1)", "reputation").String(),
+		StatusCode: http.StatusConflict,
+	}); errors.Is(err, ErrDuplicateReport) {
+		t.Fatalf("reputation error = %v, want error not matching %v", err, ErrDuplicateReport)
+	}
+}
@@ -0,0 +1,100 @@
+package reputation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+var (
+	// ErrDuplicateReport matches responses indicating that the caller has already
+	// submitted the same feedback about the user.
+	//
+	// The feedback endpoint used by [Client.Report] (POST /users/xuid({xuid})/feedback)
+	// responds with 409 Conflict when feedback of the same type from the caller about
+	// the user has already been recorded, so the feedback is not counted again. Only
+	// 409 Conflict responses to feedback requests match ErrDuplicateReport.
+	ErrDuplicateReport = errors.New("xsapi/reputation: duplicate report")
+	// ErrThrottled matches responses indicating that the caller has submitted too
+	// much feedback recently and should wait before retrying.
+	ErrThrottled = errors.New("xsapi/reputation: throttled")
+)
+
+// ResponseError carries details of an unsuccessful response returned by the
+// Xbox Live Reputation API.
+type ResponseError struct {
+	// Method is the HTTP request method, if available.
+	Method string
+	// URL is the HTTP request URL, if available.
+	URL string
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+	// Code is the service error code, if the response body included one.
+	Code int
+	// Description is the service error description, if present.
+	Description string
+	// Source is the service error source, if present.
+	Source string
+	// RetryAfter is the server-requested delay before retrying, if present.
+	RetryAfter time.Duration
+}
+
+// Error implements error by formatting e as a Reputation API response failure.
+func (e *ResponseError) Error() string {
+	prefix := ""
+	if e.Method != "" && e.URL != "" {
+		prefix = e.Method + " " + e.URL + ": "
+	}
+	if e.Code != 0 && e.Description != "" {
+		return fmt.Sprintf("%sxsapi/reputation: request failed: status=%d code=%d description=%q", prefix, e.StatusCode, e.Code, e.Description)
+	}
+	if e.Code != 0 {
+		return fmt.Sprintf("%sxsapi/reputation: request failed: status=%d code=%d", prefix, e.StatusCode, e.Code)
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%sxsapi/reputation: request failed: status=%d retry_after=%s", prefix, e.StatusCode, e.RetryAfter)
+	}
+	return fmt.Sprintf("%sxsapi/reputation: request failed: status=%d", prefix, e.StatusCode)
+}
+
+// Is implements errors.Is matching for Reputation API error categories.
+func (e *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrDuplicateReport:
+		return e.StatusCode == http.StatusConflict && e.Method == http.MethodPost && isFeedbackURL(e.URL)
+	case ErrThrottled:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// responseError builds a ResponseError from an unsuccessful Reputation API response.
+func responseError(resp *http.Response) error {
+	d := internal.ParseResponse(resp)
+	return &ResponseError{
+		Method:      d.Method,
+		URL:         d.URL,
+		StatusCode:  d.StatusCode,
+		Code:        d.Code,
+		Description: d.Description,
+		Source:      d.Source,
+		RetryAfter:  d.RetryAfter,
+	}
+}
+
+// isFeedbackURL reports whether rawURL locates to the feedback endpoint used by
+// [Client.Report] for a user.
+func isFeedbackURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(u.Host, endpoint.Host) {
+		return false
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	return len(segments) == 3 && segments[0] == "users" && strings.HasPrefix(segments[1], "xuid(") && segments[2] == "feedback"
+}
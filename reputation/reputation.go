@@ -0,0 +1,83 @@
+package reputation
+
+// FeedbackType describes the kind of feedback submitted about a user with
+// [Client.Report].
+type FeedbackType string
+
+const (
+	// FeedbackFairPlayKillsTeammates reports a user who kills their teammates.
+	FeedbackFairPlayKillsTeammates FeedbackType = "FairPlayKillsTeammates"
+	// FeedbackFairPlayCheater reports a user who cheats.
+	FeedbackFairPlayCheater FeedbackType = "FairPlayCheater"
+	// FeedbackFairPlayTampering reports a user who tampers with the title or its content.
+	FeedbackFairPlayTampering FeedbackType = "FairPlayTampering"
+	// FeedbackFairPlayUnsporting reports a user who behaves in an unsporting manner.
+	FeedbackFairPlayUnsporting FeedbackType = "FairPlayUnsporting"
+	// FeedbackFairPlayLeaver reports a user who leaves games before they end.
+	FeedbackFairPlayLeaver FeedbackType = "FairPlayLeaver"
+	// FeedbackFairPlayQuitter reports a user who quits games before they end.
+	FeedbackFairPlayQuitter FeedbackType = "FairPlayQuitter"
+	// FeedbackFairPlayIdler reports a user who idles during games.
+	FeedbackFairPlayIdler FeedbackType = "FairPlayIdler"
+
+	// FeedbackCommsTextMessage reports an inappropriate text message.
+	FeedbackCommsTextMessage FeedbackType = "CommsTextMessage"
+	// FeedbackCommsVoiceMessage reports an inappropriate voice message.
+	FeedbackCommsVoiceMessage FeedbackType = "CommsVoiceMessage"
+	// FeedbackCommsPictureMessage reports an inappropriate picture message.
+	FeedbackCommsPictureMessage FeedbackType = "CommsPictureMessage"
+	// FeedbackCommsInappropriateVideo reports inappropriate video communication.
+	FeedbackCommsInappropriateVideo FeedbackType = "CommsInappropriateVideo"
+	// FeedbackCommsAbusiveVoice reports abusive voice communication.
+	FeedbackCommsAbusiveVoice FeedbackType = "CommsAbusiveVoice"
+
+	// FeedbackUserContentGamertag reports an offensive gamertag.
+	FeedbackUserContentGamertag FeedbackType = "UserContentGamertag"
+	// FeedbackUserContentRealName reports an offensive real name.
+	FeedbackUserContentRealName FeedbackType = "UserContentRealName"
+	// FeedbackUserContentMotto reports an offensive motto.
+	FeedbackUserContentMotto FeedbackType = "UserContentMotto"
+	// FeedbackUserContentAvatarImage reports an offensive avatar image.
+	FeedbackUserContentAvatarImage FeedbackType = "UserContentAvatarImage"
+	// FeedbackUserContentLocation reports an offensive location.
+	FeedbackUserContentLocation FeedbackType = "UserContentLocation"
+	// FeedbackUserContentScreenshot reports an offensive screenshot.
+	FeedbackUserContentScreenshot FeedbackType = "UserContentScreenshot"
+	// FeedbackUserContentGameDVR reports an offensive game clip.
+	FeedbackUserContentGameDVR FeedbackType = "UserContentGameDVR"
+	// FeedbackUserContentActivityFeed reports an offensive activity feed post.
+	FeedbackUserContentActivityFeed FeedbackType = "UserContentActivityFeed"
+	// FeedbackUserContentInappropriateUGC reports inappropriate user-generated content.
+	FeedbackUserContentInappropriateUGC FeedbackType = "UserContentInappropriateUGC"
+	// FeedbackUserContentReviewRequest requests a review of user-generated content.
+	FeedbackUserContentReviewRequest FeedbackType = "UserContentReviewRequest"
+
+	// FeedbackPositiveSkilledPlayer commends a skilled player.
+	FeedbackPositiveSkilledPlayer FeedbackType = "PositiveSkilledPlayer"
+	// FeedbackPositiveHelpfulPlayer commends a helpful player.
+	FeedbackPositiveHelpfulPlayer FeedbackType = "PositiveHelpfulPlayer"
+	// FeedbackPositiveHighQualityUGC commends high quality user-generated content.
+	FeedbackPositiveHighQualityUGC FeedbackType = "PositiveHighQualityUGC"
+)
+
+// Standing describes the reputation of a user as computed by Xbox Live from the
+// feedback submitted about the user.
+type Standing struct {
+	// OverallReputationIsBad reports whether the overall reputation of the user is bad,
+	// which is the case if any of the reputations below has fallen under the threshold.
+	OverallReputationIsBad bool `json:"overallReputationIsBad"`
+	// FairPlay is the reputation of the user for playing fairly.
+	FairPlay Reputation `json:"fairplayReputation"`
+	// Comms is the reputation of the user for communicating with other users.
+	Comms Reputation `json:"commsReputation"`
+	// UserContent is the reputation of the user for content created by the user.
+	UserContent Reputation `json:"userContentReputation"`
+}
+
+// Reputation is a single category of the reputation of a user.
+type Reputation struct {
+	// Score is the score of the reputation. A higher score is a better reputation.
+	Score float64 `json:"score"`
+	// Bad reports whether the reputation has fallen under the threshold.
+	Bad bool `json:"isBad"`
+}
@@ -1,13 +1,12 @@
 package social
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"strconv"
 	"time"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
 )
 
 const (
@@ -85,54 +84,14 @@ func (e *ResponseError) Is(target error) bool {
 // responseError builds a ResponseError from an unsuccessful Social or PeopleHub
 // response.
 func responseError(resp *http.Response) error {
-	responseErr := &ResponseError{
-		StatusCode: resp.StatusCode,
-		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
-	}
-	if resp.Request != nil {
-		responseErr.Method = resp.Request.Method
-		if resp.Request.URL != nil {
-			responseErr.URL = resp.Request.URL.String()
-		}
-	}
-	if resp.Body == nil {
-		return responseErr
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return responseErr
-	}
-	var data struct {
-		Code        int    `json:"code"`
-		Description string `json:"description"`
-		Source      string `json:"source"`
-	}
-	if err := json.Unmarshal(body, &data); err == nil {
-		responseErr.Code = data.Code
-		responseErr.Description = data.Description
-		responseErr.Source = data.Source
-	}
-	return responseErr
-}
-
-// parseRetryAfter parses Retry-After header values in either seconds or HTTP-date form.
-func parseRetryAfter(value string) time.Duration {
-	if value == "" {
-		return 0
-	}
-	if seconds, err := strconv.Atoi(value); err == nil {
-		if seconds <= 0 {
-			return 0
-		}
-		return time.Duration(seconds) * time.Second
-	}
-	when, err := http.ParseTime(value)
-	if err != nil {
-		return 0
-	}
-	delay := time.Until(when)
-	if delay < 0 {
-		return 0
+	d := internal.ParseResponse(resp)
+	return &ResponseError{
+		Method:      d.Method,
+		URL:         d.URL,
+		StatusCode:  d.StatusCode,
+		Code:        d.Code,
+		Description: d.Description,
+		Source:      d.Source,
+		RetryAfter:  d.RetryAfter,
 	}
-	return delay
 }
@@ -2,9 +2,11 @@ package social
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -233,13 +235,6 @@ func TestResponseErrorPreservesMetadataWhenBodyReadFails(t *testing.T) {
 	}
 }
 
-func TestParseRetryAfterHTTPDate(t *testing.T) {
-	delay := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
-	if delay <= 0 || delay > time.Hour {
-		t.Fatalf("delay = %s, want within the next hour", delay)
-	}
-}
-
 type roundTripFunc func(*http.Request) (*http.Response, error)
 
 func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -265,3 +260,89 @@ func (errReadCloser) Read([]byte) (int, error) {
 func (errReadCloser) Close() error {
 	return nil
 }
+
+func TestAddFriendsReportsPartialFailures(t *testing.T) {
+	xuids := make([]string, 10*bulkLimit)
+	for i := range xuids {
+		xuids[i] = strconv.Itoa(i)
+	}
+	release := make(chan struct{})
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body bulkRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if len(body.XUIDs) != bulkLimit {
+			t.Errorf("request contains %d XUIDs, want %d", len(body.XUIDs), bulkLimit)
+		}
+		if body.XUIDs[0] == "0" {
+			// Let the other requests in flight finish once no more chunks are sent.
+			time.AfterFunc(100*time.Millisecond, func() { close(release) })
+			return response(req, http.StatusBadRequest, `{"code":1028,"description":"full"}`), nil
+		}
+		<-release
+		updated, _ := json.Marshal(body.XUIDs[1:])
+		return response(req, http.StatusOK, `{"updatedPeople":`+string(updated)+`,"failedToUpdate":["`+body.XUIDs[0]+`"]}`), nil
+	})}, nil, xsts.UserInfo{}, nil)
+
+	result, err := client.AddFriends(context.Background(), xuids)
+	if !errors.Is(err, ErrFriendListFull) {
+		t.Fatalf("AddFriends error = %v, want %v", err, ErrFriendListFull)
+	}
+	// The first chunk failed, and the other chunks in flight were completed.
+	if got, want := len(result.Updated), (bulkConcurrency-1)*(bulkLimit-1); got != want {
+		t.Fatalf("updated %d users, want %d", got, want)
+	}
+	if got, want := len(result.Failed), bulkLimit+bulkConcurrency-1; got != want {
+		t.Fatalf("failed %d users, want %d", got, want)
+	}
+	if !errors.Is(result.Failed[0].Err, ErrFriendListFull) || !errors.Is(result.Failed[len(result.Failed)-1].Err, ErrNotUpdated) {
+		t.Fatalf("failures = %v, %v", result.Failed[0], result.Failed[len(result.Failed)-1])
+	}
+	if got, want := len(result.Skipped), len(xuids)-bulkConcurrency*bulkLimit; got != want {
+		t.Fatalf("skipped %d users, want %d", got, want)
+	}
+}
+
+func TestFavoriteReportsPartialFailures(t *testing.T) {
+	xuids := make([]string, bulkLimit+2)
+	for i := range xuids {
+		xuids[i] = strconv.Itoa(i)
+	}
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != bulkFavoritesPath || req.URL.Query().Get("method") != "add" {
+			return nil, errors.New("unexpected request " + req.URL.String())
+		}
+		var body bulkRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if len(body.XUIDs) == bulkLimit {
+			return response(req, http.StatusNoContent, ""), nil
+		}
+		return response(req, http.StatusOK, `{"updatedPeople":["`+body.XUIDs[0]+`"],"failedToUpdate":["`+body.XUIDs[1]+`"]}`), nil
+	})}, nil, xsts.UserInfo{}, nil)
+
+	result, err := client.Favorite(context.Background(), xuids)
+	if err != nil {
+		t.Fatalf("Favorite: %v", err)
+	}
+	if got, want := len(result.Updated), bulkLimit+1; got != want {
+		t.Fatalf("updated %d users, want %d", got, want)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].XUID != xuids[len(xuids)-1] || !errors.Is(result.Failed[0].Err, ErrNotUpdated) {
+		t.Fatalf("failures = %+v, want last user not updated", result.Failed)
+	}
+
+	// An empty 200 response does not report the users as updated.
+	client = New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return response(req, http.StatusOK, ""), nil
+	})}, nil, xsts.UserInfo{}, nil)
+	result, err = client.Unfavorite(context.Background(), []string{"1", "2"})
+	if err == nil {
+		t.Fatal("Unfavorite returned nil error for an empty response")
+	}
+	if len(result.Updated) != 0 || len(result.Failed) != 2 {
+		t.Fatalf("result = %+v, want all users failed", result)
+	}
+}
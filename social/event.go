@@ -0,0 +1,165 @@
+package social
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Event describes a notification delivered over the social subscription by [Client.Events].
+type Event struct {
+	// Type is the notification type of the event. It is one of the NotificationType* constants.
+	Type string
+	// XUIDs lists the XUIDs of the users affected by the change. It is only populated
+	// if Type is [NotificationTypeAdded], [NotificationTypeRemoved] or [NotificationTypeChanged].
+	XUIDs []string
+	// Users lists the users affected by the change, resolved from XUIDs using [Client.PeopleByXUIDs]
+	// with [EventsConfig.Decorations]. It is only populated if [EventsConfig.Users] is true and the users were resolved successfully.
+	Users []User
+	// Count is the current number of incoming friend requests. It is only populated
+	// if Type is [NotificationTypeIncomingFriendRequestCountChanged].
+	Count int
+	// Err is the error that occurred while resolving Users, if any.
+	Err error
+}
+
+// EventsConfig describes a configuration for receiving events with [Client.Events].
+type EventsConfig struct {
+	// Users resolves the XUIDs of the users affected by each change to [User] values
+	// before the event is delivered, which are reported in [Event.Users].
+	Users bool
+	// Decorations lists the decorations requested for the users reported in [Event.Users].
+	// If empty, the decorations returned by [DefaultDecorations] are requested. It is
+	// ignored if Users is false.
+	Decorations []Decoration
+	// Buffer is the capacity of the returned channel. If zero, a default of 16 is used.
+	Buffer int
+}
+
+// Events subscribes to the social subscription in the same way as [Client.Subscribe], and
+// returns a channel on which the events delivered over the subscription are sent. It is an
+// alternative to implementing [SubscriptionHandler].
+//
+// The channel is closed once ctx is done, at which point the events are no longer received.
+// If the subscription is lost, an event of [NotificationTypeSubscriptionLost] is sent, but
+// the channel remains open so that events are received again once the Client subscribes again.
+//
+// Events that cannot be buffered by the channel are queued in the order they were received
+// and sent by a single goroutine, so a slow receiver does not block the subscription. The
+// queue grows until the events are received, so the channel should be drained until it is
+// closed. If [EventsConfig.Users] is true, the users are resolved concurrently for each change,
+// so events of different changes may be delivered out of order.
+func (c *Client) Events(ctx context.Context, config EventsConfig) (<-chan Event, error) {
+	if config.Buffer <= 0 {
+		config.Buffer = 16
+	}
+	e := &eventHandler{
+		ctx:     ctx,
+		ch:      make(chan Event, config.Buffer),
+		pending: make(chan struct{}, 1),
+	}
+	var h SubscriptionHandler = e
+	if config.Users {
+		h = &userEventHandler{eventHandler: e, decorations: slices.Clone(config.Decorations)}
+	}
+	cancel, err := c.Subscribe(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	go e.run(cancel)
+	return e.ch, nil
+}
+
+// eventHandler is a [SubscriptionHandler] that sends events to a channel returned by [Client.Events].
+type eventHandler struct {
+	ctx context.Context
+	ch  chan Event
+
+	// queue holds the events that have not yet been sent to ch, in the order
+	// they were received. It is guarded by mu.
+	queue []Event
+	mu    sync.Mutex
+	// pending is used to notify run that an event has been queued.
+	pending chan struct{}
+}
+
+// HandleSocialNotification implements [SubscriptionHandler.HandleSocialNotification].
+func (h *eventHandler) HandleSocialNotification(typ string, xuids []string) {
+	h.send(Event{Type: typ, XUIDs: xuids})
+}
+
+// HandleIncomingFriendRequestCountChange implements [SubscriptionHandler.HandleIncomingFriendRequestCountChange].
+func (h *eventHandler) HandleIncomingFriendRequestCountChange(count int) {
+	h.send(Event{Type: NotificationTypeIncomingFriendRequestCountChanged, Count: count})
+}
+
+// HandleSubscriptionLost implements [SubscriptionHandler.HandleSubscriptionLost].
+func (h *eventHandler) HandleSubscriptionLost() {
+	h.send(Event{Type: NotificationTypeSubscriptionLost})
+}
+
+// send queues the event to be sent to the channel, unless ctx is done.
+func (h *eventHandler) send(e Event) {
+	if h.ctx.Err() != nil {
+		return
+	}
+	h.mu.Lock()
+	h.queue = append(h.queue, e)
+	h.mu.Unlock()
+	select {
+	case h.pending <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued events to the channel in order until ctx is done, at which
+// point the handler is unregistered using cancel and the channel is closed.
+func (h *eventHandler) run(cancel func()) {
+	defer close(h.ch)
+	defer cancel()
+	for {
+		h.mu.Lock()
+		if len(h.queue) == 0 {
+			h.mu.Unlock()
+			select {
+			case <-h.ctx.Done():
+				return
+			case <-h.pending:
+			}
+			continue
+		}
+		e := h.queue[0]
+		h.queue[0] = Event{}
+		h.queue = h.queue[1:]
+		h.mu.Unlock()
+
+		select {
+		case <-h.ctx.Done():
+			return
+		case h.ch <- e:
+		}
+	}
+}
+
+// userEventHandler is an eventHandler that sends events for changes in the friend list
+// once the users affected by the change have been resolved. The users are resolved once
+// per change, shared with other handlers registered via [Client.Subscribe] that request
+// the same decorations.
+type userEventHandler struct {
+	*eventHandler
+	decorations []Decoration
+}
+
+// UserDecorations implements [DecoratedUserSubscriptionHandler.UserDecorations].
+func (h *userEventHandler) UserDecorations() []Decoration {
+	return h.decorations
+}
+
+// HandleSocialNotification implements [SubscriptionHandler.HandleSocialNotification].
+// The event is sent by handleUsers once the users have been resolved.
+func (h *userEventHandler) HandleSocialNotification(string, []string) {}
+
+// handleUsers implements usersHandler.
+func (h *userEventHandler) handleUsers(typ string, xuids []string, users []User, err error) {
+	h.send(Event{Type: typ, XUIDs: xuids, Users: users, Err: err})
+}
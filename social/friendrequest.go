@@ -0,0 +1,369 @@
+package social
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FriendRequestPolicy decides whether an incoming friend request sent by the user
+// should be accepted by a [FriendRequestManager]. Any function may be used as a
+// custom policy.
+type FriendRequestPolicy func(u User) bool
+
+// AcceptAll returns a FriendRequestPolicy that accepts every incoming friend request.
+func AcceptAll() FriendRequestPolicy {
+	return func(User) bool { return true }
+}
+
+// AcceptFollowers returns a FriendRequestPolicy that only accepts incoming friend
+// requests sent by users following the caller.
+func AcceptFollowers() FriendRequestPolicy {
+	return func(u User) bool { return u.Followed }
+}
+
+// AcceptAllowlist returns a FriendRequestPolicy that only accepts incoming friend
+// requests sent by the users identified by the XUIDs.
+func AcceptAllowlist(xuids ...string) FriendRequestPolicy {
+	allowed := make(map[string]struct{}, len(xuids))
+	for _, xuid := range xuids {
+		allowed[xuid] = struct{}{}
+	}
+	return func(u User) bool {
+		_, ok := allowed[u.XUID]
+		return ok
+	}
+}
+
+// FriendEvictionStrategy selects up to n friends to remove from the friend list of the
+// caller when it is full, so that incoming friend requests can be accepted.
+type FriendEvictionStrategy func(friends []User, n int) []User
+
+// EvictOldest returns a FriendEvictionStrategy that removes the friends who have been
+// friends with the caller for the longest time, according to [User.FriendedAt].
+func EvictOldest() FriendEvictionStrategy {
+	return func(friends []User, n int) []User {
+		friends = slices.Clone(friends)
+		slices.SortStableFunc(friends, func(a, b User) int {
+			return a.FriendedAt.Compare(b.FriendedAt)
+		})
+		return friends[:min(n, len(friends))]
+	}
+}
+
+// FriendRequestConfig describes a configuration for automating friend requests
+// using [Client.FriendRequests].
+type FriendRequestConfig struct {
+	// Policy decides which incoming friend requests are accepted.
+	// If nil, [AcceptAll] is used.
+	Policy FriendRequestPolicy
+	// Decline declines incoming friend requests that are not accepted by Policy.
+	// If false, such requests are left pending.
+	Decline bool
+
+	// Evict selects friends to remove when the friend list of the caller is full. If nil,
+	// no friends are removed, and incoming friend requests are left pending until the
+	// friend list has room again.
+	//
+	// Since the service reports the same error when the friend list of the sender is full,
+	// friends are only removed if the caller has at least FriendLimit friends, and at most
+	// one friend is removed for each incoming friend request.
+	Evict FriendEvictionStrategy
+	// FriendLimit is the maximum number of friends the caller may have. It is used to tell
+	// whether the friend list of the caller is full before removing friends with Evict.
+	// If zero, a default of 1000 is used.
+	FriendLimit int
+
+	// OutgoingTTL is the duration after which friend requests sent by the caller that
+	// are still pending are canceled. Since the time a request was sent is not reported
+	// by Xbox Live, it is measured from the time the request was first observed by the
+	// manager. If zero, outgoing friend requests never expire.
+	OutgoingTTL time.Duration
+
+	// MinInterval is the minimum interval between relationship changes made by the manager,
+	// which can be used to stay below the rate limits of the service. When the service
+	// responds with [ErrRateLimited], the manager also waits for the requested delay.
+	MinInterval time.Duration
+	// PollInterval is the interval at which friend requests are processed regardless of
+	// notifications received over the social subscription. If zero, a default of five
+	// minutes is used.
+	PollInterval time.Duration
+}
+
+// FriendRequests starts a FriendRequestManager that processes friend requests using the
+// configuration until [FriendRequestManager.Close] is called.
+//
+// Incoming friend requests are processed whenever the social subscription reports
+// a change in the number of incoming friend requests, and periodically using
+// [FriendRequestConfig.PollInterval].
+func (c *Client) FriendRequests(ctx context.Context, config FriendRequestConfig) (*FriendRequestManager, error) {
+	if config.Policy == nil {
+		config.Policy = AcceptAll()
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Minute * 5
+	}
+	if config.FriendLimit <= 0 {
+		config.FriendLimit = defaultFriendLimit
+	}
+	m := &FriendRequestManager{
+		client:    c,
+		config:    config,
+		outgoing:  make(map[string]time.Time),
+		evicted:   make(map[string]struct{}),
+		processCh: make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+	}
+	unsubscribe, err := c.Subscribe(ctx, friendRequestHandler{m})
+	if err != nil {
+		return nil, fmt.Errorf("xsapi/social: subscribe: %w", err)
+	}
+	m.unsubscribe = unsubscribe
+	m.requestProcess()
+	go m.run()
+	return m, nil
+}
+
+// FriendRequestManager accepts, declines and expires friend requests of the caller
+// according to a [FriendRequestConfig]. It is safe for concurrent use.
+type FriendRequestManager struct {
+	client *Client
+	config FriendRequestConfig
+
+	// outgoing holds the time at which each pending outgoing friend request
+	// was first observed, keyed by the XUID of the recipient.
+	outgoing map[string]time.Time
+	// evicted holds the XUIDs of the senders of pending incoming friend requests
+	// for which a friend has already been removed.
+	evicted map[string]struct{}
+	// lastChange is the time of the last relationship change made by the manager.
+	lastChange time.Time
+	// mu serializes processing friend requests and guards the fields above.
+	mu sync.Mutex
+
+	// processCh is used to request processing of friend requests.
+	processCh chan struct{}
+	// unsubscribe unregisters the manager from the social subscription.
+	unsubscribe func()
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Close stops the FriendRequestManager from processing friend requests.
+func (m *FriendRequestManager) Close() error {
+	m.closeOnce.Do(func() {
+		m.unsubscribe()
+		close(m.closed)
+	})
+	return nil
+}
+
+// requestProcess schedules processing of friend requests. Multiple requests
+// made while processing is pending are coalesced into one.
+func (m *FriendRequestManager) requestProcess() {
+	select {
+	case m.processCh <- struct{}{}:
+	default:
+	}
+}
+
+// run processes friend requests until the manager is closed.
+func (m *FriendRequestManager) run() {
+	t := time.NewTicker(m.config.PollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-m.closed:
+			return
+		case <-t.C:
+		case <-m.processCh:
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-m.closed:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		err := m.process(ctx)
+		cancel()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			m.client.log.Error("error processing friend requests", "err", err)
+		}
+	}
+}
+
+// process processes the incoming and outgoing friend requests of the caller once.
+func (m *FriendRequestManager) process(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	incoming, err := m.client.IncomingFriendRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("list incoming friend requests: %w", err)
+	}
+	// Forget the requests that are no longer pending.
+	maps.DeleteFunc(m.evicted, func(xuid string, _ struct{}) bool {
+		return !slices.ContainsFunc(incoming, func(u User) bool { return u.XUID == xuid })
+	})
+	for _, u := range incoming {
+		if !m.config.Policy(u) {
+			if m.config.Decline {
+				if err := m.change(ctx, func() error { return m.client.RemoveFriend(ctx, u.XUID) }); err != nil {
+					return fmt.Errorf("decline friend request from %s: %w", u.XUID, err)
+				}
+			}
+			continue
+		}
+		err := m.change(ctx, func() error { return m.client.AddFriend(ctx, u.XUID) })
+		if errors.Is(err, ErrFriendListFull) && m.config.Evict != nil {
+			evicted, evictErr := m.evict(ctx, u.XUID)
+			if evictErr != nil {
+				return evictErr
+			}
+			if !evicted {
+				// Either the friend list of the sender is full, or a friend has already
+				// been removed for the request, so it is left pending.
+				m.client.log.Warn("friend list is full, leaving friend request pending", "xuid", u.XUID)
+				continue
+			}
+			err = m.change(ctx, func() error { return m.client.AddFriend(ctx, u.XUID) })
+		}
+		if errors.Is(err, ErrFriendListFull) {
+			// The remaining requests are left pending until the friend list has room again.
+			m.client.log.Warn("friend list is full, leaving friend requests pending")
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("accept friend request from %s: %w", u.XUID, err)
+		}
+	}
+
+	if m.config.OutgoingTTL <= 0 {
+		return nil
+	}
+	outgoing, err := m.client.OutgoingFriendRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("list outgoing friend requests: %w", err)
+	}
+	now := time.Now()
+	pending := make(map[string]time.Time, len(outgoing))
+	for _, u := range outgoing {
+		observed, ok := m.outgoing[u.XUID]
+		if !ok {
+			observed = now
+		}
+		if now.Sub(observed) < m.config.OutgoingTTL {
+			pending[u.XUID] = observed
+			continue
+		}
+		if err := m.change(ctx, func() error { return m.client.RemoveFriend(ctx, u.XUID) }); err != nil {
+			m.outgoing = pending
+			return fmt.Errorf("cancel friend request to %s: %w", u.XUID, err)
+		}
+	}
+	m.outgoing = pending
+	return nil
+}
+
+// evict removes a friend selected by [FriendRequestConfig.Evict] to make room for the
+// incoming friend request sent by the user identified by the XUID, and reports whether
+// a friend has been removed. No friend is removed if one has already been removed for
+// the request, or if the caller has fewer friends than [FriendRequestConfig.FriendLimit],
+// in which case it is the friend list of the sender that is full.
+func (m *FriendRequestManager) evict(ctx context.Context, xuid string) (bool, error) {
+	if _, ok := m.evicted[xuid]; ok {
+		return false, nil
+	}
+	var friends []User
+	for u, err := range m.client.AllFriends(ctx, PeopleListConfig{Undecorated: true}) {
+		if err != nil {
+			return false, fmt.Errorf("list friends: %w", err)
+		}
+		friends = append(friends, u)
+	}
+	if len(friends) < m.config.FriendLimit {
+		return false, nil
+	}
+	selected := m.config.Evict(friends, 1)
+	if len(selected) == 0 {
+		return false, nil
+	}
+	for _, u := range selected {
+		if err := m.change(ctx, func() error { return m.client.RemoveFriend(ctx, u.XUID) }); err != nil {
+			return false, fmt.Errorf("evict friend %s: %w", u.XUID, err)
+		}
+	}
+	m.evicted[xuid] = struct{}{}
+	return true, nil
+}
+
+// defaultFriendLimit is the maximum number of friends a user may have on Xbox Live,
+// used if [FriendRequestConfig.FriendLimit] is zero.
+const defaultFriendLimit = 1000
+
+// change makes a relationship change using fn, waiting for [FriendRequestConfig.MinInterval]
+// since the previous change. If the service responds with [ErrRateLimited], the change is
+// retried once after the requested delay. m.mu must be held.
+func (m *FriendRequestManager) change(ctx context.Context, fn func() error) error {
+	if err := sleep(ctx, time.Until(m.lastChange.Add(m.config.MinInterval))); err != nil {
+		return err
+	}
+	err := fn()
+	m.lastChange = time.Now()
+	var responseErr *ResponseError
+	if errors.Is(err, ErrRateLimited) && errors.As(err, &responseErr) {
+		if err := sleep(ctx, cmp.Or(responseErr.RetryAfter, time.Minute)); err != nil {
+			return err
+		}
+		err = fn()
+		m.lastChange = time.Now()
+	}
+	return err
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// friendRequestHandler is a [SubscriptionHandler] that requests processing of friend
+// requests when the number of incoming friend requests of the caller changes.
+type friendRequestHandler struct {
+	*FriendRequestManager
+}
+
+// HandleSocialNotification implements [SubscriptionHandler.HandleSocialNotification].
+func (friendRequestHandler) HandleSocialNotification(string, []string) {}
+
+// HandleIncomingFriendRequestCountChange implements [SubscriptionHandler.HandleIncomingFriendRequestCountChange].
+func (h friendRequestHandler) HandleIncomingFriendRequestCountChange(count int) {
+	select {
+	case <-h.closed:
+	default:
+		if count > 0 {
+			h.requestProcess()
+		}
+	}
+}
+
+// HandleSubscriptionLost implements [SubscriptionHandler.HandleSubscriptionLost].
+func (friendRequestHandler) HandleSubscriptionLost() {}
@@ -0,0 +1,90 @@
+package social
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+func TestFriendRequestManagerEvictsOldestFriend(t *testing.T) {
+	tests := []struct {
+		name        string
+		friendLimit int
+		// full is the number of times the friend list is reported full when
+		// accepting the friend request.
+		full int
+		want []string
+	}{
+		{
+			name:        "friend list of caller is full",
+			friendLimit: 2,
+			full:        1,
+			want:        []string{"PUT 5", "DELETE 2", "PUT 5", "DELETE 6"},
+		},
+		{
+			name:        "friend list of sender is full",
+			friendLimit: 3,
+			full:        1,
+			want:        []string{"PUT 5", "DELETE 6"},
+		},
+		{
+			name:        "friend list is still full after eviction",
+			friendLimit: 2,
+			full:        3,
+			// The remaining requests are left pending once the friend list is still full,
+			// and no friend is removed again for the same request when processed again.
+			want: []string{"PUT 5", "DELETE 2", "PUT 5", "PUT 5", "DELETE 6"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			full := tt.full
+			client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				path := req.URL.Path
+				switch {
+				case strings.Contains(path, "/people/friendRequests(received)"):
+					return response(req, http.StatusOK, `{"people":[{"xuid":"5"},{"xuid":"6"}]}`), nil
+				case strings.Contains(path, "/people/friends") && req.Host == "peoplehub.xboxlive.com":
+					return response(req, http.StatusOK, `{"people":[
+						{"xuid":"1","isFriend":true,"friendedDateTimeUtc":"2024-01-01T00:00:00Z"},
+						{"xuid":"2","isFriend":true,"friendedDateTimeUtc":"2020-01-01T00:00:00Z"}
+					]}`), nil
+				}
+				xuid := path[strings.LastIndex(path, "(")+1 : len(path)-1]
+				requests = append(requests, req.Method+" "+xuid)
+				if req.Method == http.MethodPut && full > 0 {
+					full--
+					return response(req, http.StatusBadRequest, `{"code":1028,"description":"full"}`), nil
+				}
+				return response(req, http.StatusOK, ``), nil
+			})}, nil, xsts.UserInfo{}, nil)
+
+			m := &FriendRequestManager{
+				client: client,
+				config: FriendRequestConfig{
+					Policy:      AcceptAllowlist("5"),
+					Decline:     true,
+					Evict:       EvictOldest(),
+					FriendLimit: tt.friendLimit,
+				},
+				evicted: make(map[string]struct{}),
+			}
+			for range 2 {
+				if err := m.process(context.Background()); err != nil {
+					t.Fatalf("process: %v", err)
+				}
+				if full == 0 {
+					break
+				}
+			}
+			if !slices.Equal(requests, tt.want) {
+				t.Fatalf("requests = %q, want %q", requests, tt.want)
+			}
+		})
+	}
+}
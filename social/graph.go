@@ -0,0 +1,573 @@
+package social
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+	"github.com/df-mc/go-xsapi/v2/presence"
+)
+
+// GraphConfig describes a configuration for loading a social graph using [Client.Graph].
+type GraphConfig struct {
+	// Presence, if non-nil, is used to retrieve the presence of every user in the graph,
+	// which is exposed as [GraphUser.Presence]. Since presence changes are not delivered
+	// over the social subscription, presence is refreshed periodically using PresenceInterval.
+	Presence *presence.Client
+	// PresenceInterval is the interval at which presence is refreshed. If zero, a default
+	// of one minute is used. It has no effect unless Presence is non-nil.
+	PresenceInterval time.Duration
+	// PageSize is the number of users requested in each page while loading the friend,
+	// follower and following lists. If zero, the default of [PeopleListConfig.PageSize] is used.
+	PageSize int
+	// Handler receives changes made to the graph and its groups. If nil,
+	// [NopGraphHandler] is used.
+	Handler GraphHandler
+}
+
+// GraphUser is a user in a social graph.
+type GraphUser struct {
+	User
+	// Presence is the presence of the user. It is nil if [GraphConfig.Presence] is nil,
+	// or if the presence of the user could not be retrieved.
+	Presence *presence.Presence
+}
+
+// Online reports whether the user is currently online according to [GraphUser.Presence].
+func (u GraphUser) Online() bool {
+	return u.Presence != nil && u.Presence.State == "Online"
+}
+
+// Playing reports whether the user is currently active in the title identified by the
+// ID according to [GraphUser.Presence].
+func (u GraphUser) Playing(titleID uint32) bool {
+	if u.Presence == nil {
+		return false
+	}
+	for _, device := range u.Presence.Devices {
+		for _, title := range device.Titles {
+			if title.ID == titleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GraphChange describes a change made to a social graph or one of its groups.
+type GraphChange struct {
+	// Added lists the users added to the graph or group.
+	Added []GraphUser
+	// Removed lists the users removed from the graph or group, in their last known state.
+	Removed []GraphUser
+	// Changed lists the users in the graph or group whose profile, relationship
+	// or presence has changed, in their new state.
+	Changed []GraphUser
+}
+
+// empty reports whether the GraphChange contains no changes.
+func (c GraphChange) empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// GraphHandler receives changes made to a social graph. It can be registered on a
+// Graph via [GraphConfig.Handler]. Methods are called in the order the changes are
+// applied to the graph and must not block for long.
+type GraphHandler interface {
+	// HandleGraphChange is called when users are added to, removed from or changed
+	// in the graph.
+	HandleGraphChange(change GraphChange)
+	// HandleGroupChange is called when users are added to, removed from or changed
+	// in the group identified by the name, as registered via [Graph.AddGroup].
+	HandleGroupChange(name string, change GraphChange)
+}
+
+// NopGraphHandler is a no-op implementation of [GraphHandler].
+type NopGraphHandler struct{}
+
+// HandleGraphChange implements [GraphHandler.HandleGraphChange].
+func (NopGraphHandler) HandleGraphChange(GraphChange) {}
+
+// HandleGroupChange implements [GraphHandler.HandleGroupChange].
+func (NopGraphHandler) HandleGroupChange(string, GraphChange) {}
+
+// HandleGraphStale implements [GraphStaleHandler.HandleGraphStale].
+func (NopGraphHandler) HandleGraphStale(error) {}
+
+// GraphStaleHandler may be implemented by a [GraphHandler] to be notified when a Graph
+// is no longer kept up to date.
+type GraphStaleHandler interface {
+	GraphHandler
+
+	// HandleGraphStale is called when the social subscription keeping the graph up to
+	// date has been lost and could not be restored, or the graph could not be reloaded
+	// after restoring it. err describes why. Changes are no longer reported until
+	// [Graph.Refresh] succeeds after the subscription has been restored, such as by
+	// creating another Graph with [Client.Graph].
+	HandleGraphStale(err error)
+}
+
+// Graph is a local view of the caller's social graph, modeled after the social manager
+// of the Xbox Live SDK. It holds every user who is a friend of, followed by or following
+// the caller, and is kept up to date using the social subscription of the Client.
+//
+// Named groups of users matching a filter can be registered using [Graph.AddGroup], such as
+// online friends or friends playing a title, and changes to each group are reported to the
+// [GraphHandler]. Graph is safe for concurrent use.
+type Graph struct {
+	client *Client
+	config GraphConfig
+
+	users  map[string]GraphUser
+	groups map[string]*graphGroup
+	// mu guards users and groups from concurrent access.
+	mu sync.RWMutex
+	// updateMu serializes updates so that changes are computed and
+	// reported in the order they are applied.
+	updateMu sync.Mutex
+	// fetchMu serializes retrieving users and applying them to the graph, so
+	// that a slower, older retrieval cannot overwrite the result of a newer one.
+	fetchMu sync.Mutex
+
+	// unsubscribe unregisters the graph from the social subscription.
+	unsubscribe func()
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// graphGroup is a named group of users registered via [Graph.AddGroup].
+type graphGroup struct {
+	filter  func(GraphUser) bool
+	members map[string]struct{}
+}
+
+// Graph loads the caller's social graph and keeps it up to date until [Graph.Close] is called.
+//
+// The friend, follower and following lists are loaded using [Client.AllPeople]. Users added,
+// removed or changed according to the social subscription are retrieved again using
+// [Client.UsersByXUIDs], and are removed from the graph once they are neither a friend of,
+// followed by nor following the caller.
+func (c *Client) Graph(ctx context.Context, config GraphConfig) (*Graph, error) {
+	if config.PresenceInterval <= 0 {
+		config.PresenceInterval = time.Minute
+	}
+	if config.Handler == nil {
+		config.Handler = NopGraphHandler{}
+	}
+	g := &Graph{
+		client: c,
+		config: config,
+		users:  make(map[string]GraphUser),
+		groups: make(map[string]*graphGroup),
+		closed: make(chan struct{}),
+	}
+	if err := g.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	unsubscribe, err := c.Subscribe(ctx, graphHandler{g})
+	if err != nil {
+		return nil, fmt.Errorf("xsapi/social: subscribe: %w", err)
+	}
+	g.unsubscribe = unsubscribe
+	if config.Presence != nil {
+		go g.pollPresence()
+	}
+	return g, nil
+}
+
+// Users returns every user in the graph sorted by their gamertag.
+func (g *Graph) Users() []GraphUser {
+	return g.filter(func(GraphUser) bool { return true })
+}
+
+// User returns the user identified by the XUID. The boolean result reports
+// whether the user is in the graph.
+func (g *Graph) User(xuid string) (GraphUser, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	u, ok := g.users[xuid]
+	return u, ok
+}
+
+// Friends returns the friends of the caller sorted by their gamertag.
+func (g *Graph) Friends() []GraphUser {
+	return g.filter(func(u GraphUser) bool { return u.Friend })
+}
+
+// Followers returns the users following the caller sorted by their gamertag.
+func (g *Graph) Followers() []GraphUser {
+	return g.filter(func(u GraphUser) bool { return u.Followed })
+}
+
+// Following returns the users followed by the caller sorted by their gamertag.
+func (g *Graph) Following() []GraphUser {
+	return g.filter(func(u GraphUser) bool { return u.Following })
+}
+
+// AddGroup registers a group of users in the graph matching the filter, and returns the
+// users currently in the group sorted by their gamertag. Once registered, changes to the
+// group are reported to [GraphHandler.HandleGroupChange] with the name. If a group with
+// the name is already registered, it is replaced.
+//
+// The filter may read the graph, such as by calling [Graph.User], but must not call
+// [Graph.AddGroup], [Graph.RemoveGroup] or [Graph.Refresh].
+//
+// For example, online friends can be grouped with a filter such as:
+//
+//	func(u social.GraphUser) bool { return u.Friend && u.Online() }
+func (g *Graph) AddGroup(name string, filter func(GraphUser) bool) []GraphUser {
+	g.updateMu.Lock()
+	defer g.updateMu.Unlock()
+
+	// The filter is called without holding the lock, so that it may read the Graph.
+	// The users are replaced instead of modified in place, and cannot be replaced
+	// meanwhile as updates are serialized by updateMu.
+	g.mu.RLock()
+	current := g.users
+	g.mu.RUnlock()
+
+	group := &graphGroup{filter: filter, members: make(map[string]struct{})}
+	for xuid, u := range current {
+		if filter(u) {
+			group.members[xuid] = struct{}{}
+		}
+	}
+	g.mu.Lock()
+	g.groups[name] = group
+	g.mu.Unlock()
+
+	users, _ := g.Group(name)
+	return users
+}
+
+// RemoveGroup unregisters the group identified by the name.
+func (g *Graph) RemoveGroup(name string) {
+	g.updateMu.Lock()
+	defer g.updateMu.Unlock()
+	g.mu.Lock()
+	delete(g.groups, name)
+	g.mu.Unlock()
+}
+
+// Group returns the users in the group identified by the name sorted by their gamertag.
+// The boolean result reports whether the group has been registered via [Graph.AddGroup].
+func (g *Graph) Group(name string) ([]GraphUser, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	group, ok := g.groups[name]
+	if !ok {
+		return nil, false
+	}
+	users := make([]GraphUser, 0, len(group.members))
+	for xuid := range group.members {
+		users = append(users, g.users[xuid])
+	}
+	sortGraphUsers(users)
+	return users, true
+}
+
+// Refresh loads the friend, follower and following lists of the caller again and replaces
+// the users in the graph, reporting any differences to the [GraphHandler]. It is called by
+// [Client.Graph], and again once the social subscription has been restored after it was lost.
+func (g *Graph) Refresh(ctx context.Context) error {
+	g.fetchMu.Lock()
+	defer g.fetchMu.Unlock()
+
+	conf := PeopleListConfig{PageSize: g.config.PageSize}
+	users := make(map[string]GraphUser)
+	for _, list := range []PeopleList{PeopleListFriends, PeopleListFollowers, PeopleListFollowing} {
+		for u, err := range g.client.AllPeople(ctx, list, conf) {
+			if err != nil {
+				return fmt.Errorf("xsapi/social: load %s: %w", list, err)
+			}
+			users[u.XUID] = GraphUser{User: u}
+		}
+	}
+	if err := g.loadPresence(ctx, users); err != nil {
+		return err
+	}
+	g.update(func(current map[string]GraphUser) {
+		clear(current)
+		maps.Copy(current, users)
+	})
+	return nil
+}
+
+// Close stops the Graph from being updated. The users in the graph remain accessible.
+func (g *Graph) Close() error {
+	g.closeOnce.Do(func() {
+		g.unsubscribe()
+		close(g.closed)
+	})
+	return nil
+}
+
+// refreshUsers retrieves the users identified by the XUIDs again and updates them in the
+// graph. Users who are not returned, or who no longer have a relationship with the caller,
+// are removed from the graph.
+func (g *Graph) refreshUsers(ctx context.Context, xuids []string) error {
+	g.fetchMu.Lock()
+	defer g.fetchMu.Unlock()
+
+	profiles, err := g.client.UsersByXUIDs(ctx, xuids)
+	if err != nil {
+		return fmt.Errorf("xsapi/social: retrieve users: %w", err)
+	}
+	users := make(map[string]GraphUser, len(profiles))
+	for _, u := range profiles {
+		if u.Friend || u.Following || u.Followed {
+			users[u.XUID] = GraphUser{User: u}
+		}
+	}
+	if err := g.loadPresence(ctx, users); err != nil {
+		return err
+	}
+	g.update(func(current map[string]GraphUser) {
+		for _, xuid := range xuids {
+			if u, ok := users[xuid]; ok {
+				current[xuid] = u
+			} else {
+				delete(current, xuid)
+			}
+		}
+	})
+	return nil
+}
+
+// loadPresence retrieves the presence of the users using [GraphConfig.Presence], if any,
+// and stores it into each user.
+func (g *Graph) loadPresence(ctx context.Context, users map[string]GraphUser) error {
+	if g.config.Presence == nil || len(users) == 0 {
+		return nil
+	}
+	xuids := slices.Sorted(maps.Keys(users))
+	for chunk := range slices.Chunk(xuids, internal.PresenceBatchLimit) {
+		presences, err := g.config.Presence.Batch(ctx, presence.BatchRequest{
+			XUIDs: chunk,
+			Depth: presence.DepthAll,
+		})
+		if err != nil {
+			return fmt.Errorf("xsapi/social: retrieve presence: %w", err)
+		}
+		for _, p := range presences {
+			if p == nil {
+				continue
+			}
+			if u, ok := users[p.XUID]; ok {
+				u.Presence = p
+				users[p.XUID] = u
+			}
+		}
+	}
+	return nil
+}
+
+// pollPresence refreshes the presence of the users in the graph until it is closed.
+func (g *Graph) pollPresence() {
+	t := time.NewTicker(g.config.PresenceInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-g.closed:
+			return
+		case <-t.C:
+		}
+
+		if err := g.refreshPresence(); err != nil && !errors.Is(err, context.Canceled) {
+			g.client.log.Error("error refreshing presence of social graph", "err", err)
+		}
+	}
+}
+
+// refreshPresence retrieves the presence of the users in the graph again and updates
+// it in the graph.
+func (g *Graph) refreshPresence() error {
+	g.fetchMu.Lock()
+	defer g.fetchMu.Unlock()
+
+	g.mu.RLock()
+	users := maps.Clone(g.users)
+	g.mu.RUnlock()
+	for xuid, u := range users {
+		u.Presence = nil
+		users[xuid] = u
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+	if err := g.loadPresence(ctx, users); err != nil {
+		return err
+	}
+	g.update(func(current map[string]GraphUser) {
+		for xuid, u := range current {
+			if updated, ok := users[xuid]; ok {
+				u.Presence = updated.Presence
+				current[xuid] = u
+			}
+		}
+	})
+	return nil
+}
+
+// update applies the changes made by fn to a copy of the users in the graph, and reports
+// the differences to the [GraphHandler] for the graph and each group.
+func (g *Graph) update(fn func(users map[string]GraphUser)) {
+	g.updateMu.Lock()
+	defer g.updateMu.Unlock()
+
+	g.mu.RLock()
+	prev := g.users
+	g.mu.RUnlock()
+	next := maps.Clone(prev)
+	fn(next)
+
+	var (
+		change  GraphChange
+		changed []string
+	)
+	for xuid, u := range next {
+		old, ok := prev[xuid]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, u)
+		case !reflect.DeepEqual(old, u):
+			change.Changed = append(change.Changed, u)
+		default:
+			continue
+		}
+		changed = append(changed, xuid)
+	}
+	for xuid, u := range prev {
+		if _, ok := next[xuid]; !ok {
+			change.Removed = append(change.Removed, u)
+			changed = append(changed, xuid)
+		}
+	}
+
+	// The filters are called without holding the lock, so that they may read the
+	// Graph. Groups cannot be registered meanwhile, as it requires holding updateMu.
+	matches := make(map[string]map[string]bool, len(g.groups))
+	for name, group := range g.groups {
+		match := make(map[string]bool, len(changed))
+		for _, xuid := range changed {
+			u, ok := next[xuid]
+			match[xuid] = ok && group.filter(u)
+		}
+		matches[name] = match
+	}
+
+	groupChanges := make(map[string]GraphChange)
+	g.mu.Lock()
+	g.users = next
+	for name, group := range g.groups {
+		var groupChange GraphChange
+		for _, xuid := range changed {
+			_, was := group.members[xuid]
+			u := next[xuid]
+			is := matches[name][xuid]
+			switch {
+			case is && !was:
+				group.members[xuid] = struct{}{}
+				groupChange.Added = append(groupChange.Added, u)
+			case !is && was:
+				delete(group.members, xuid)
+				groupChange.Removed = append(groupChange.Removed, prev[xuid])
+			case is && was:
+				groupChange.Changed = append(groupChange.Changed, u)
+			}
+		}
+		if !groupChange.empty() {
+			groupChanges[name] = groupChange
+		}
+	}
+	g.mu.Unlock()
+
+	if !change.empty() {
+		g.config.Handler.HandleGraphChange(change)
+	}
+	for _, name := range slices.Sorted(maps.Keys(groupChanges)) {
+		g.config.Handler.HandleGroupChange(name, groupChanges[name])
+	}
+}
+
+// filter returns the users in the graph matching the filter sorted by their gamertag.
+func (g *Graph) filter(filter func(GraphUser) bool) []GraphUser {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var users []GraphUser
+	for _, u := range g.users {
+		if filter(u) {
+			users = append(users, u)
+		}
+	}
+	sortGraphUsers(users)
+	return users
+}
+
+// sortGraphUsers sorts the users by their gamertag, case-insensitively.
+func sortGraphUsers(users []GraphUser) {
+	slices.SortFunc(users, func(a, b GraphUser) int {
+		return cmp.Or(
+			cmp.Compare(strings.ToLower(a.GamerTag), strings.ToLower(b.GamerTag)),
+			cmp.Compare(a.XUID, b.XUID),
+		)
+	})
+}
+
+// graphHandler is a [SubscriptionHandler] that updates a Graph when the
+// caller's friend list changes.
+type graphHandler struct {
+	*Graph
+}
+
+// HandleSocialNotification implements [SubscriptionHandler.HandleSocialNotification].
+func (h graphHandler) HandleSocialNotification(_ string, xuids []string) {
+	select {
+	case <-h.closed:
+		return
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+	if err := h.refreshUsers(ctx, xuids); err != nil && !errors.Is(err, context.Canceled) {
+		h.client.log.Error("error updating social graph", "err", err)
+	}
+}
+
+// HandleIncomingFriendRequestCountChange implements [SubscriptionHandler.HandleIncomingFriendRequestCountChange].
+func (graphHandler) HandleIncomingFriendRequestCountChange(int) {}
+
+// HandleSubscriptionLost implements [SubscriptionHandler.HandleSubscriptionLost].
+// The subscription is restored and the graph is reloaded, as changes may have been
+// missed meanwhile. If either fails, the [GraphStaleHandler] is notified, if any.
+func (h graphHandler) HandleSubscriptionLost() {
+	select {
+	case <-h.closed:
+		return
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+	err := h.client.restoreSubscription(ctx)
+	if err != nil {
+		err = fmt.Errorf("xsapi/social: restore subscription: %w", err)
+	} else {
+		err = h.Refresh(ctx)
+	}
+	if err == nil {
+		return
+	}
+	h.client.log.Error("social graph is no longer updated as the subscription was lost", "err", err)
+	if handler, ok := h.config.Handler.(GraphStaleHandler); ok {
+		handler.HandleGraphStale(err)
+	}
+}
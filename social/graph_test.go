@@ -0,0 +1,190 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/rta"
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+func TestGraphUpdatesUsersAndGroups(t *testing.T) {
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch path := req.URL.Path; {
+		case strings.Contains(path, "/people/friends/"):
+			return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"a","isFriend":true}]}`), nil
+		case strings.Contains(path, "/people/followers/"):
+			return response(req, http.StatusOK, `{"people":[{"xuid":"2","gamertag":"b","isFollowingCaller":true}]}`), nil
+		case strings.Contains(path, "/people/batch/"):
+			// The follower has become a friend, and the friend has removed the caller.
+			return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"a"},{"xuid":"2","gamertag":"b","isFriend":true,"isFollowingCaller":true}]}`), nil
+		default:
+			return response(req, http.StatusOK, `{"people":[]}`), nil
+		}
+	})}, stubProvider{}, xsts.UserInfo{XUID: "0"}, nil)
+
+	type event struct {
+		group  string
+		change GraphChange
+	}
+	var events []event
+	g, err := client.Graph(context.Background(), GraphConfig{Handler: graphFunc(func(group string, change GraphChange) {
+		events = append(events, event{group, change})
+	})})
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	defer g.Close()
+	if got := xuidsOf(g.Users()); got != "1,2" {
+		t.Fatalf("users = %s, want 1,2", got)
+	}
+	// The filter reads the graph, which must not deadlock.
+	friends := func(u GraphUser) bool {
+		_, ok := g.User(u.XUID)
+		return ok && u.Friend
+	}
+	if got := xuidsOf(g.AddGroup("friends", friends)); got != "1" {
+		t.Fatalf("friends group = %s, want 1", got)
+	}
+
+	events = nil
+	graphHandler{g}.HandleSocialNotification(NotificationTypeChanged, []string{"1", "2"})
+	if got := xuidsOf(g.Users()); got != "2" {
+		t.Fatalf("users = %s, want 2", got)
+	}
+	if got, _ := g.Group("friends"); xuidsOf(got) != "2" {
+		t.Fatalf("friends group = %s, want 2", xuidsOf(got))
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want graph and group changes", events)
+	}
+	if graph := events[0].change; events[0].group != "" || xuidsOf(graph.Removed) != "1" || xuidsOf(graph.Changed) != "2" || len(graph.Added) != 0 {
+		t.Fatalf("graph change = %+v", events[0])
+	}
+	if group := events[1].change; events[1].group != "friends" || xuidsOf(group.Removed) != "1" || xuidsOf(group.Added) != "2" || len(group.Changed) != 0 {
+		t.Fatalf("group change = %+v", events[1])
+	}
+}
+
+func xuidsOf(users []GraphUser) string {
+	xuids := make([]string, 0, len(users))
+	for _, u := range users {
+		xuids = append(xuids, u.XUID)
+	}
+	return strings.Join(xuids, ",")
+}
+
+type graphFunc func(group string, change GraphChange)
+
+func (f graphFunc) HandleGraphChange(change GraphChange) { f("", change) }
+
+func (f graphFunc) HandleGroupChange(name string, change GraphChange) { f(name, change) }
+
+type stubProvider struct{}
+
+func (stubProvider) Subscribe(context.Context, *rta.Subscription) error   { return nil }
+func (stubProvider) Unsubscribe(context.Context, *rta.Subscription) error { return nil }
+
+func TestGraphRefreshesAfterSubscriptionLost(t *testing.T) {
+	var restored atomic.Bool
+	provider := &flakyProvider{}
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/people/friends/") && restored.Load() {
+			// A friend has been added while the subscription was lost.
+			return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"a","isFriend":true}]}`), nil
+		}
+		return response(req, http.StatusOK, `{"people":[]}`), nil
+	})}, provider, xsts.UserInfo{XUID: "0"}, nil)
+
+	var (
+		changes []GraphChange
+		stale   []error
+	)
+	g, err := client.Graph(context.Background(), GraphConfig{Handler: &staleGraphHandler{
+		graphFunc: func(_ string, change GraphChange) { changes = append(changes, change) },
+		stale:     func(err error) { stale = append(stale, err) },
+	}})
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	defer g.Close()
+
+	restored.Store(true)
+	graphHandler{g}.HandleSubscriptionLost()
+	if len(stale) != 0 {
+		t.Fatalf("stale errors = %v, want none", stale)
+	}
+	if len(changes) != 1 || xuidsOf(changes[0].Added) != "1" {
+		t.Fatalf("changes = %+v, want friend added after refresh", changes)
+	}
+
+	provider.fail.Store(true)
+	graphHandler{g}.HandleSubscriptionLost()
+	if len(stale) != 1 {
+		t.Fatalf("stale errors = %v, want one after the subscription could not be restored", stale)
+	}
+}
+
+func TestGraphAppliesUserRefreshesInOrder(t *testing.T) {
+	var (
+		batches atomic.Int32
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if !strings.Contains(req.URL.Path, "/people/batch/") {
+			return response(req, http.StatusOK, `{"people":[]}`), nil
+		}
+		if batches.Add(1) == 1 {
+			// The first retrieval is slow and returns the older state of the user.
+			close(started)
+			<-release
+			return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"old","isFriend":true}]}`), nil
+		}
+		return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"new","isFriend":true}]}`), nil
+	})}, stubProvider{}, xsts.UserInfo{XUID: "0"}, nil)
+	g, err := client.Graph(context.Background(), GraphConfig{})
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	defer g.Close()
+
+	var wg sync.WaitGroup
+	wg.Go(func() { graphHandler{g}.HandleSocialNotification(NotificationTypeAdded, []string{"1"}) })
+	<-started
+	wg.Go(func() { graphHandler{g}.HandleSocialNotification(NotificationTypeChanged, []string{"1"}) })
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if u, _ := g.User("1"); u.GamerTag != "new" {
+		t.Fatalf("gamertag = %q, want the newer retrieval", u.GamerTag)
+	}
+}
+
+type staleGraphHandler struct {
+	graphFunc
+	stale func(error)
+}
+
+func (h *staleGraphHandler) HandleGraphStale(err error) { h.stale(err) }
+
+// flakyProvider is an [rta.Provider] whose subscriptions fail once fail is set.
+type flakyProvider struct {
+	fail atomic.Bool
+}
+
+func (p *flakyProvider) Subscribe(context.Context, *rta.Subscription) error {
+	if p.fail.Load() {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func (p *flakyProvider) Unsubscribe(context.Context, *rta.Subscription) error { return nil }
@@ -0,0 +1,257 @@
+package social
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/go-xsapi/v2/internal"
+)
+
+// Permission identifies an action the caller may perform on a target user, which
+// is validated against the privacy settings, privileges, block list and mute list
+// of both users by [Client.CheckPermission] and [Client.CheckPermissions].
+type Permission string
+
+// Permissions known to be validated by the Xbox Live Privacy API.
+const (
+	PermissionCommunicateUsingText                 Permission = "CommunicateUsingText"
+	PermissionCommunicateUsingVoice                Permission = "CommunicateUsingVoice"
+	PermissionCommunicateUsingVideo                Permission = "CommunicateUsingVideo"
+	PermissionPlayMultiplayer                      Permission = "PlayMultiplayer"
+	PermissionViewTargetPresence                   Permission = "ViewTargetPresence"
+	PermissionViewTargetProfile                    Permission = "ViewTargetProfile"
+	PermissionViewTargetGameHistory                Permission = "ViewTargetGameHistory"
+	PermissionViewTargetVideoHistory               Permission = "ViewTargetVideoHistory"
+	PermissionViewTargetMusicHistory               Permission = "ViewTargetMusicHistory"
+	PermissionViewTargetExerciseInfo               Permission = "ViewTargetExerciseInfo"
+	PermissionViewTargetVideoStatus                Permission = "ViewTargetVideoStatus"
+	PermissionViewTargetMusicStatus                Permission = "ViewTargetMusicStatus"
+	PermissionViewTargetUserCreatedContent         Permission = "ViewTargetUserCreatedContent"
+	PermissionBroadcastWithTwitch                  Permission = "BroadcastWithTwitch"
+	PermissionWriteComment                         Permission = "WriteComment"
+	PermissionShareItem                            Permission = "ShareItem"
+	PermissionShareTargetContentToExternalNetworks Permission = "ShareTargetContentToExternalNetworks"
+)
+
+// DenyReason describes why a [Permission] was denied to the caller.
+type DenyReason string
+
+const (
+	// DenyReasonNotAllowed indicates that the permission is not allowed for an unspecified reason.
+	DenyReasonNotAllowed DenyReason = "NotAllowed"
+	// DenyReasonMissingPrivilege indicates that the caller lacks the privilege required
+	// for the permission, reported in [PermissionReason.RestrictedPrivilege].
+	DenyReasonMissingPrivilege DenyReason = "MissingPrivilege"
+	// DenyReasonPrivilegeRestrictsTarget indicates that a privilege of the caller restricts
+	// the permission for the target user, such as a child account restricted to friends.
+	DenyReasonPrivilegeRestrictsTarget DenyReason = "PrivilegeRestrictsTarget"
+	// DenyReasonBlockListRestrictsTarget indicates that either user has blocked the other.
+	DenyReasonBlockListRestrictsTarget DenyReason = "BlockListRestrictsTarget"
+	// DenyReasonMuteListRestrictsTarget indicates that the caller has muted the target user.
+	DenyReasonMuteListRestrictsTarget DenyReason = "MuteListRestrictsTarget"
+	// DenyReasonPrivacySettingsRestrictsTarget indicates that a privacy setting of the target
+	// user restricts the permission, reported in [PermissionReason.RestrictedSetting].
+	DenyReasonPrivacySettingsRestrictsTarget DenyReason = "PrivacySettingsRestrictsTarget"
+	// DenyReasonUnknown indicates that the reason is not known.
+	DenyReasonUnknown DenyReason = "Unknown"
+)
+
+// PermissionReason describes a single reason a [Permission] was denied.
+type PermissionReason struct {
+	// Reason is the reason the permission was denied.
+	Reason DenyReason `json:"reason"`
+	// RestrictedPrivilege is the privilege that caused the denial, if the
+	// Reason is [DenyReasonMissingPrivilege] or [DenyReasonPrivilegeRestrictsTarget].
+	RestrictedPrivilege string `json:"restrictedPrivilege,omitempty"`
+	// RestrictedSetting is the privacy setting that caused the denial, if
+	// the Reason is [DenyReasonPrivacySettingsRestrictsTarget].
+	RestrictedSetting string `json:"restrictedSetting,omitempty"`
+}
+
+// PermissionResult describes whether the caller is allowed to perform
+// an action on a target user.
+type PermissionResult struct {
+	// XUID is the XUID of the target user.
+	XUID string `json:"-"`
+	// Permission is the permission that was checked.
+	Permission Permission `json:"permission"`
+	// Allowed reports whether the permission was granted.
+	Allowed bool `json:"isAllowed"`
+	// Reasons lists the reasons the permission was denied. It is empty if Allowed is true.
+	Reasons []PermissionReason `json:"reasons,omitempty"`
+}
+
+// DeniedBy reports whether the permission was denied for the reason.
+func (r PermissionResult) DeniedBy(reason DenyReason) bool {
+	return slices.ContainsFunc(r.Reasons, func(r PermissionReason) bool {
+		return r.Reason == reason
+	})
+}
+
+// ErrPermissionDenied matches errors returned by [Client.RequirePermission]
+// when a permission was denied to the caller.
+var ErrPermissionDenied = errors.New("xsapi/social: permission denied")
+
+// PermissionError is returned by [Client.RequirePermission] when a permission was
+// denied to the caller. It matches [ErrPermissionDenied] using [errors.Is].
+type PermissionError struct {
+	PermissionResult
+}
+
+// Error implements error by formatting the denied permission and its reasons.
+func (e *PermissionError) Error() string {
+	reasons := make([]string, len(e.Reasons))
+	for i, r := range e.Reasons {
+		reasons[i] = string(r.Reason)
+	}
+	return fmt.Sprintf("xsapi/social: permission %s denied for %s: reasons=[%s]", e.Permission, e.XUID, strings.Join(reasons, ","))
+}
+
+// Is implements errors.Is matching for [ErrPermissionDenied].
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// CheckPermission checks whether the caller is allowed to perform the action described
+// by the permission on the user identified by the XUID. A denied permission is not
+// reported as an error; the reasons are included in the returned PermissionResult.
+func (c *Client) CheckPermission(ctx context.Context, xuid string, permission Permission, opts ...internal.RequestOption) (PermissionResult, error) {
+	requestURL := privacyEndpoint.JoinPath("/users/xuid(" + c.userInfo.XUID + ")/permission/validate")
+	requestURL.RawQuery = url.Values{
+		"setting": {string(permission)},
+		"target":  {"xuid(" + xuid + ")"},
+	}.Encode()
+
+	result := PermissionResult{XUID: xuid, Permission: permission}
+	if err := internal.Do(ctx, c.client, http.MethodGet, requestURL.String(), nil, &result, append(opts,
+		internal.DefaultLanguage,
+		internal.ContractVersion("1"),
+		internal.RequestHeader("Accept", "application/json"),
+		internal.RequestHeader("Cache-Control", "no-cache"),
+	)); err != nil {
+		return PermissionResult{}, err
+	}
+	result.XUID, result.Permission = xuid, permission
+	return result, nil
+}
+
+// CheckPermissions checks whether the caller is allowed to perform the actions described
+// by the permissions on each user identified by the XUIDs. The returned map contains the
+// results for each unique XUID, sorted in the same order as the permissions. Results for
+// permissions not requested, if reported by the service, are sorted last.
+//
+// Requests for many users are split into chunks of up to 100 users.
+func (c *Client) CheckPermissions(ctx context.Context, xuids []string, permissions []Permission, opts ...internal.RequestOption) (map[string][]PermissionResult, error) {
+	unique := slices.Clone(xuids)
+	slices.Sort(unique)
+	unique = slices.Compact(unique)
+
+	results := make(map[string][]PermissionResult, len(unique))
+	for chunk := range slices.Chunk(unique, permissionBatchLimit) {
+		users := make([]permissionUser, len(chunk))
+		for i, xuid := range chunk {
+			users[i] = permissionUser{XUID: xuid}
+		}
+		var resp permissionBatchResponse
+		if err := internal.Do(ctx, c.client, http.MethodPost, privacyEndpoint.JoinPath("/users/xuid("+c.userInfo.XUID+")/permission/validate").String(), permissionBatchRequest{
+			Users:       users,
+			Permissions: permissions,
+		}, &resp, append(opts,
+			internal.DefaultLanguage,
+			internal.ContractVersion("1"),
+			internal.RequestHeader("Content-Type", "application/json"),
+			internal.RequestHeader("Accept", "application/json"),
+			internal.RequestHeader("Cache-Control", "no-cache"),
+		)); err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Responses {
+			for _, p := range r.Permissions {
+				p.XUID = r.User.XUID
+				results[r.User.XUID] = append(results[r.User.XUID], p)
+			}
+		}
+	}
+	// The service does not guarantee the order of the results for each user.
+	order := func(r PermissionResult) int {
+		if i := slices.Index(permissions, r.Permission); i != -1 {
+			return i
+		}
+		return len(permissions)
+	}
+	for _, r := range results {
+		slices.SortStableFunc(r, func(a, b PermissionResult) int {
+			return cmp.Compare(order(a), order(b))
+		})
+	}
+	return results, nil
+}
+
+// RequirePermission checks whether the caller is allowed to perform the actions described by
+// the permissions on the user identified by the XUID in a single request, and returns a
+// [*PermissionError] for the first permission that was denied. It returns nil if all
+// permissions were granted.
+func (c *Client) RequirePermission(ctx context.Context, xuid string, permissions []Permission, opts ...internal.RequestOption) error {
+	results, err := c.CheckPermissions(ctx, []string{xuid}, permissions, opts...)
+	if err != nil {
+		return fmt.Errorf("check permissions: %w", err)
+	}
+	for _, permission := range permissions {
+		i := slices.IndexFunc(results[xuid], func(r PermissionResult) bool {
+			return r.Permission == permission
+		})
+		if i == -1 {
+			return fmt.Errorf("xsapi/social: permission %s not reported for %s", permission, xuid)
+		}
+		if result := results[xuid][i]; !result.Allowed {
+			return &PermissionError{PermissionResult: result}
+		}
+	}
+	return nil
+}
+
+// CheckInvitePermission checks whether the caller is allowed to play multiplayer with the user
+// identified by the XUID. It allows the Client to be used as an [mpsd.InvitePermissionChecker]
+// in [mpsd.InviteOptions] so that invites sent to users who cannot accept them are rejected
+// before reaching the service.
+//
+// [mpsd.InvitePermissionChecker]: https://pkg.go.dev/github.com/df-mc/go-xsapi/v2/mpsd#InvitePermissionChecker
+// [mpsd.InviteOptions]: https://pkg.go.dev/github.com/df-mc/go-xsapi/v2/mpsd#InviteOptions
+func (c *Client) CheckInvitePermission(ctx context.Context, xuid string) error {
+	return c.RequirePermission(ctx, xuid, []Permission{PermissionPlayMultiplayer})
+}
+
+// permissionBatchLimit is the maximum number of users whose permissions
+// are checked in a single request by [Client.CheckPermissions].
+const permissionBatchLimit = 100
+
+type (
+	// permissionBatchRequest is the request body used by [Client.CheckPermissions].
+	permissionBatchRequest struct {
+		// Users lists the target users whose permissions are checked.
+		Users []permissionUser `json:"users"`
+		// Permissions lists the permissions checked for each user.
+		Permissions []Permission `json:"permissions"`
+	}
+	// permissionUser identifies a target user in a batch permission check.
+	permissionUser struct {
+		// XUID is the XUID of the user.
+		XUID string `json:"xuid"`
+	}
+	// permissionBatchResponse is the response body returned for a permissionBatchRequest.
+	permissionBatchResponse struct {
+		// Responses contains the permission results for each user.
+		Responses []struct {
+			// User identifies the target user.
+			User permissionUser `json:"user"`
+			// Permissions contains the results of each permission checked for the user.
+			Permissions []PermissionResult `json:"permissions"`
+		} `json:"responses"`
+	}
+)
@@ -0,0 +1,87 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+func TestCheckPermissionsReturnsTypedReasons(t *testing.T) {
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/users/xuid(1)/permission/validate" {
+			return nil, fmt.Errorf("unexpected path %q", req.URL.Path)
+		}
+		switch req.Method {
+		case http.MethodPost:
+			var body permissionBatchRequest
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			if len(body.Users) == 1 && body.Users[0].XUID == "3" && len(body.Permissions) == 1 {
+				return response(req, http.StatusOK, `{"responses":[
+					{"user":{"xuid":"3"},"permissions":[{"isAllowed":false,"permission":"PlayMultiplayer","reasons":[{"reason":"BlockListRestrictsTarget"}]}]}
+				]}`), nil
+			}
+			if len(body.Users) != 2 || len(body.Permissions) != 2 {
+				return nil, fmt.Errorf("unexpected request body %+v", body)
+			}
+			return response(req, http.StatusOK, `{"responses":[
+				{"user":{"xuid":"2"},"permissions":[{"isAllowed":true,"permission":"CommunicateUsingText"},{"isAllowed":true,"permission":"PlayMultiplayer"}]},
+				{"user":{"xuid":"3"},"permissions":[{"isAllowed":false,"permission":"PlayMultiplayer","reasons":[{"reason":"BlockListRestrictsTarget"}]},{"isAllowed":false,"permission":"CommunicateUsingText","reasons":[{"reason":"MuteListRestrictsTarget"},{"reason":"PrivacySettingsRestrictsTarget","restrictedSetting":"CommunicateUsingText"}]}]}
+			]}`), nil
+		case http.MethodGet:
+			if setting, target := req.URL.Query().Get("setting"), req.URL.Query().Get("target"); setting != "PlayMultiplayer" || target != "xuid(3)" {
+				return nil, fmt.Errorf("unexpected query %q", req.URL.RawQuery)
+			}
+			return response(req, http.StatusOK, `{"isAllowed":false,"reasons":[{"reason":"BlockListRestrictsTarget"}]}`), nil
+		default:
+			return nil, fmt.Errorf("unexpected method %s", req.Method)
+		}
+	})}, nil, xsts.UserInfo{XUID: "1"}, nil)
+
+	results, err := client.CheckPermissions(context.Background(), []string{"2", "3", "2"}, []Permission{PermissionPlayMultiplayer, PermissionCommunicateUsingText})
+	if err != nil {
+		t.Fatalf("CheckPermissions: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want 2 users", results)
+	}
+	for _, r := range results["2"] {
+		if !r.Allowed || r.XUID != "2" {
+			t.Fatalf("result for 2 = %+v, want allowed", r)
+		}
+	}
+	// The results are reordered to match the permissions, regardless of the order in the response.
+	if got := results["2"]; len(got) != 2 || got[0].Permission != PermissionPlayMultiplayer || got[1].Permission != PermissionCommunicateUsingText {
+		t.Fatalf("results for 2 = %+v, want in the order of the permissions", got)
+	}
+	denied := results["3"]
+	if len(denied) != 2 || denied[0].Allowed || !denied[0].DeniedBy(DenyReasonBlockListRestrictsTarget) {
+		t.Fatalf("results for 3 = %+v, want PlayMultiplayer denied by block list", denied)
+	}
+	if !denied[1].DeniedBy(DenyReasonMuteListRestrictsTarget) || denied[1].Reasons[1].RestrictedSetting != "CommunicateUsingText" {
+		t.Fatalf("CommunicateUsingText result = %+v, want mute list and privacy setting reasons", denied[1])
+	}
+
+	result, err := client.CheckPermission(context.Background(), "3", PermissionPlayMultiplayer)
+	if err != nil {
+		t.Fatalf("CheckPermission: %v", err)
+	}
+	if result.Allowed || result.XUID != "3" || !result.DeniedBy(DenyReasonBlockListRestrictsTarget) {
+		t.Fatalf("CheckPermission result = %+v, want denied by block list", result)
+	}
+
+	err = client.CheckInvitePermission(context.Background(), "3")
+	var permissionErr *PermissionError
+	if !errors.Is(err, ErrPermissionDenied) || !errors.As(err, &permissionErr) {
+		t.Fatalf("CheckInvitePermission error = %v, want %v", err, ErrPermissionDenied)
+	}
+	if permissionErr.XUID != "3" || permissionErr.Permission != PermissionPlayMultiplayer || !permissionErr.DeniedBy(DenyReasonBlockListRestrictsTarget) {
+		t.Fatalf("permission error = %+v", permissionErr)
+	}
+}
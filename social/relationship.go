@@ -3,9 +3,13 @@ package social
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/df-mc/go-xsapi/v2/internal"
 )
@@ -96,59 +100,149 @@ func (c *Client) deleteRelationship(ctx context.Context, xuid, relationship stri
 }
 
 // AddFriends creates or accepts friend relationships with all users
-// identified by the given XUIDs in a single request, and returns the XUIDs
-// that Xbox Live reports as updated. It behaves like [Client.AddFriend] for
-// each user, but a single bulk call avoids per-user rate limits when
-// accepting many pending requests at once.
-func (c *Client) AddFriends(ctx context.Context, xuids []string, opts ...internal.RequestOption) ([]string, error) {
-	requestURL := socialEndpoint.JoinPath(
-		"/bulk/users/me/people/friends/v2",
-	)
-	q := requestURL.Query()
-	q.Set("method", "add")
-	requestURL.RawQuery = q.Encode()
+// identified by the given XUIDs. It behaves like [Client.AddFriend] for
+// each user, but bulk calls avoid per-user rate limits when accepting many
+// pending requests at once.
+//
+// The XUIDs are split into chunks accepted by the service, which are sent
+// with bounded concurrency. See [BulkResult] for how partial failures are
+// reported. If the friend list of the caller becomes full, no further chunks
+// are sent and the remaining XUIDs are reported as skipped. The returned
+// error joins the errors of the requests that failed, and the BulkResult is
+// populated even if an error is returned.
+func (c *Client) AddFriends(ctx context.Context, xuids []string, opts ...internal.RequestOption) (BulkResult, error) {
+	return c.bulkRelationships(ctx, bulkFriendsPath, url.Values{
+		"method": []string{"add"},
+	}, xuids, opts, http.StatusOK, http.StatusCreated)
+}
 
-	req, err := internal.WithJSONBody(ctx, http.MethodPost, requestURL.String(), bulkFriendsRequest{XUIDs: xuids}, append(
-		opts,
-		socialContractVersion,
-		internal.RequestHeader("Accept", "application/json"),
-		internal.RequestHeader("Content-Type", "application/json"),
-		internal.RequestHeader("Cache-Control", "no-cache"),
-		internal.DefaultLanguage,
-	))
-	if err != nil {
-		return nil, fmt.Errorf("make request: %w", err)
-	}
+// RemoveFriends removes or denies friend relationships with all users identified
+// by XUIDs. The XUIDs are split into chunks in the same way as [Client.AddFriends].
+func (c *Client) RemoveFriends(ctx context.Context, xuids []string, opts ...internal.RequestOption) (BulkResult, error) {
+	return c.bulkRelationships(ctx, bulkFriendsPath, url.Values{
+		"method":              []string{"remove"},
+		"deleteRelationships": []string{"friends"},
+	}, xuids, opts, http.StatusOK)
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated:
-		var result bulkFriendsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf("decode response body: %w", err)
+// Favorite adds the users identified by the XUIDs to the favorites of the caller. Only users
+// followed by the caller may be added to the favorites, and favorites are reported by
+// [User.Favorite]. The XUIDs are split into chunks and partial failures are reported in the
+// same way as [Client.AddFriends].
+func (c *Client) Favorite(ctx context.Context, xuids []string, opts ...internal.RequestOption) (BulkResult, error) {
+	return c.bulkRelationships(ctx, bulkFavoritesPath, url.Values{
+		"method": []string{"add"},
+	}, xuids, opts, http.StatusOK, http.StatusNoContent)
+}
+
+// Unfavorite removes the users identified by the XUIDs from the favorites of the caller.
+// The users are still followed by the caller. The XUIDs are split into chunks and partial
+// failures are reported in the same way as [Client.AddFriends].
+func (c *Client) Unfavorite(ctx context.Context, xuids []string, opts ...internal.RequestOption) (BulkResult, error) {
+	return c.bulkRelationships(ctx, bulkFavoritesPath, url.Values{
+		"method": []string{"remove"},
+	}, xuids, opts, http.StatusOK, http.StatusNoContent)
+}
+
+// ErrNotUpdated is reported in [BulkFailure.Err] for users whose relationship was
+// not updated by a bulk request, without the service specifying the reason.
+var ErrNotUpdated = errors.New("xsapi/social: relationship not updated")
+
+// BulkResult describes the result of a bulk relationship operation, such as [Client.AddFriends].
+//
+// A bulk operation is split into chunks sent in separate requests. The XUIDs in a chunk that
+// could not be sent, or that the service reports as not updated, are listed in Failed. Once the
+// operation is stopped, such as when the friend list of the caller is full, the XUIDs in chunks
+// that have not been sent are listed in Skipped.
+type BulkResult struct {
+	// Updated lists the XUIDs whose relationships were updated.
+	Updated []string
+	// Failed lists the XUIDs whose relationships could not be updated.
+	Failed []BulkFailure
+	// Skipped lists the XUIDs that were not sent because the operation was stopped.
+	Skipped []string
+}
+
+// BulkFailure describes a user whose relationship could not be updated by a bulk operation.
+type BulkFailure struct {
+	// XUID is the XUID of the user.
+	XUID string
+	// Err is the reason of the failure. It is either the error returned for the request
+	// that included the user, or [ErrNotUpdated] if the service did not update the user.
+	Err error
+}
+
+const (
+	// bulkLimit is the maximum number of XUIDs included in a single
+	// request to a bulk relationship endpoint.
+	bulkLimit = 100
+	// bulkConcurrency is the maximum number of concurrent requests made
+	// to a bulk relationship endpoint by a single bulk operation.
+	bulkConcurrency = 4
+
+	// bulkFriendsPath is the path of the bulk endpoint used to mutate friend relationships.
+	bulkFriendsPath = "/bulk/users/me/people/friends/v2"
+	// bulkFavoritesPath is the path of the bulk endpoint used to mutate favorites.
+	bulkFavoritesPath = "/users/me/people/favorites/xuids"
+)
+
+// bulkRelationships sends the XUIDs to the bulk relationship endpoint at the path with the
+// query in chunks of bulkLimit, and merges the result of each chunk in order. The returned
+// error joins the errors returned for the requests that failed.
+func (c *Client) bulkRelationships(ctx context.Context, path string, query url.Values, xuids []string, opts []internal.RequestOption, successCodes ...int) (BulkResult, error) {
+	chunks := slices.Collect(slices.Chunk(xuids, bulkLimit))
+	var (
+		results = make([]BulkResult, len(chunks))
+		errs    = make([]error, len(chunks))
+		sem     = make(chan struct{}, bulkConcurrency)
+		stopped atomic.Bool
+		wg      sync.WaitGroup
+	)
+	for i, chunk := range chunks {
+		sem <- struct{}{}
+		if stopped.Load() || ctx.Err() != nil {
+			<-sem
+			results[i].Skipped = chunk
+			continue
 		}
-		return result.UpdatedPeople, nil
-	default:
-		return nil, responseError(resp)
+		wg.Go(func() {
+			defer func() { <-sem }()
+			updated, failed, err := c.bulkRelationshipsChunk(ctx, path, query, chunk, opts, successCodes)
+			if err != nil {
+				if errors.Is(err, ErrFriendListFull) {
+					stopped.Store(true)
+				}
+				errs[i] = err
+				for _, xuid := range chunk {
+					results[i].Failed = append(results[i].Failed, BulkFailure{XUID: xuid, Err: err})
+				}
+				return
+			}
+			results[i].Updated = updated
+			for _, xuid := range failed {
+				results[i].Failed = append(results[i].Failed, BulkFailure{XUID: xuid, Err: ErrNotUpdated})
+			}
+		})
+	}
+	wg.Wait()
+
+	var result BulkResult
+	for _, r := range results {
+		result.Updated = append(result.Updated, r.Updated...)
+		result.Failed = append(result.Failed, r.Failed...)
+		result.Skipped = append(result.Skipped, r.Skipped...)
 	}
+	return result, errors.Join(errs...)
 }
 
-// RemoveFriends removes or denies friend relationships with all users identified
-// by XUIDs.
-func (c *Client) RemoveFriends(ctx context.Context, xuids []string, opts ...internal.RequestOption) ([]string, error) {
-	requestURL := socialEndpoint.JoinPath(
-		"/bulk/users/me/people/friends/v2",
-	)
-	q := requestURL.Query()
-	q.Set("method", "remove")
-	q.Set("deleteRelationships", "friends")
-	requestURL.RawQuery = q.Encode()
+// bulkRelationshipsChunk sends a single request to the bulk relationship endpoint at the path
+// with the query for the XUIDs, and returns the XUIDs reported as updated and failed to update.
+// If the endpoint responds with 204 No Content, all XUIDs are reported as updated.
+func (c *Client) bulkRelationshipsChunk(ctx context.Context, path string, query url.Values, xuids []string, opts []internal.RequestOption, successCodes []int) (updated, failed []string, err error) {
+	requestURL := socialEndpoint.JoinPath(path)
+	requestURL.RawQuery = query.Encode()
 
-	req, err := internal.WithJSONBody(ctx, http.MethodPost, requestURL.String(), bulkFriendsRequest{XUIDs: xuids}, append(
+	req, err := internal.WithJSONBody(ctx, http.MethodPost, requestURL.String(), bulkRequest{XUIDs: xuids}, append(
 		opts,
 		socialContractVersion,
 		internal.RequestHeader("Accept", "application/json"),
@@ -157,37 +251,38 @@ func (c *Client) RemoveFriends(ctx context.Context, xuids []string, opts ...inte
 		internal.DefaultLanguage,
 	))
 	if err != nil {
-		return nil, fmt.Errorf("make request: %w", err)
+		return nil, nil, fmt.Errorf("make request: %w", err)
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var result bulkFriendsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf("decode response body: %w", err)
-		}
-		return result.UpdatedPeople, nil
-	default:
-		return nil, responseError(resp)
+	if !slices.Contains(successCodes, resp.StatusCode) {
+		return nil, nil, responseError(resp)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return xuids, nil, nil
+	}
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("decode response body: %w", err)
 	}
+	return result.UpdatedPeople, result.FailedToUpdate, nil
 }
 
 type (
-	// bulkFriendsRequest is the wire representation of a bulk friend mutation
-	// request body.
-	bulkFriendsRequest struct {
-		// XUIDs lists the XUIDs of the users whose friend relationships are mutated.
+	// bulkRequest is the wire representation of a bulk relationship
+	// mutation request body.
+	bulkRequest struct {
+		// XUIDs lists the XUIDs of the users whose relationships are mutated.
 		XUIDs []string `json:"xuids"`
 	}
 
-	// bulkFriendsResponse is the response body returned by the bulk friends
-	// endpoint.
-	bulkFriendsResponse struct {
+	// bulkResponse is the response body returned by the bulk relationship
+	// endpoints.
+	bulkResponse struct {
 		// UpdatedPeople lists the XUIDs whose relationships were updated by the request.
 		UpdatedPeople []string `json:"updatedPeople"`
 		// FailedToUpdate lists the XUIDs whose relationships couldn't be updated by the request.
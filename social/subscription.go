@@ -6,6 +6,8 @@ import (
 	"errors"
 	"log/slog"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/df-mc/go-xsapi/v2/rta"
 )
@@ -20,10 +22,17 @@ import (
 // to avoid exceeding RTA's maximum subscription limit. Subsequent calls
 // reuse the existing subscription and append h to the list of active handlers.
 //
-// Subscribe returns an error if h is nil.
-func (c *Client) Subscribe(ctx context.Context, h SubscriptionHandler) (err error) {
+// If h also implements [UserSubscriptionHandler], the XUIDs of the users affected
+// by each change are resolved to users before [UserSubscriptionHandler.HandleUsers]
+// is called. The decorations requested for the users may be selected by implementing
+// [DecoratedUserSubscriptionHandler].
+//
+// The returned cancel function unregisters h so that no further events are dispatched
+// to it. It does not close the RTA subscription, which is shared with other handlers
+// and closed by [Client.CloseContext]. Subscribe returns an error if h is nil.
+func (c *Client) Subscribe(ctx context.Context, h SubscriptionHandler) (cancel func(), err error) {
 	if h == nil {
-		return errors.New("xsapi/social: cannot subscribe with a nil SubscriptionHandler")
+		return nil, errors.New("xsapi/social: cannot subscribe with a nil SubscriptionHandler")
 	}
 
 	c.subscriptionMu.Lock()
@@ -31,12 +40,42 @@ func (c *Client) Subscribe(ctx context.Context, h SubscriptionHandler) (err erro
 
 	if !c.subscription.Active() {
 		if err := c.rta.Subscribe(ctx, c.subscription); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	c.subscriptionHandlers = append(c.subscriptionHandlers, h)
-	return nil
+	// Handlers are wrapped so that they can be identified for removal,
+	// even if the handler itself is not comparable.
+	r := &registeredHandler{h}
+	c.subscriptionHandlers = append(c.subscriptionHandlers, r)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.subscriptionMu.Lock()
+			defer c.subscriptionMu.Unlock()
+			c.subscriptionHandlers = slices.DeleteFunc(c.subscriptionHandlers, func(h SubscriptionHandler) bool {
+				return h == SubscriptionHandler(r)
+			})
+		})
+	}, nil
+}
+
+// restoreSubscription subscribes to the RTA services again after the subscription has been
+// lost, keeping the handlers registered via [Client.Subscribe]. It is a no-op if the
+// subscription is active.
+func (c *Client) restoreSubscription(ctx context.Context) error {
+	c.subscriptionMu.Lock()
+	defer c.subscriptionMu.Unlock()
+	if c.subscription.Active() {
+		return nil
+	}
+	return c.rta.Subscribe(ctx, c.subscription)
+}
+
+// registeredHandler wraps a SubscriptionHandler registered via [Client.Subscribe]
+// so that it can be unregistered by pointer identity.
+type registeredHandler struct {
+	SubscriptionHandler
 }
 
 // subscriptionHandler is an internal implementation of [rta.SubscriptionHandler]
@@ -61,11 +100,11 @@ func (h *subscriptionHandler) HandleEvent(custom json.RawMessage) {
 		Type string `json:"NotificationType"`
 
 		// Count is the current number of incoming friend requests.
-		// It is only populated when Type is notificationTypeIncomingFriendRequestCountChanged.
+		// It is only populated when Type is NotificationTypeIncomingFriendRequestCountChanged.
 		Count *int `json:"Count"`
 
 		// XUIDs lists the XUIDs of users affected by the change.
-		// It is only populated when Type is one of the exported NotificationType* constants defined below.
+		// It is only populated when Type is NotificationTypeAdded, NotificationTypeRemoved or NotificationTypeChanged.
 		XUIDs []string `json:"Xuids"`
 	}
 	if err := json.Unmarshal(custom, &data); err != nil {
@@ -77,7 +116,7 @@ func (h *subscriptionHandler) HandleEvent(custom json.RawMessage) {
 	}
 
 	switch data.Type {
-	case notificationTypeIncomingFriendRequestCountChanged:
+	case NotificationTypeIncomingFriendRequestCountChanged:
 		if data.Count == nil {
 			h.log.Error("friend request count is absent from subscription event payload",
 				slog.String("custom", string(custom)),
@@ -97,9 +136,43 @@ func (h *subscriptionHandler) HandleEvent(custom json.RawMessage) {
 			return
 		}
 
-		for _, handler := range h.handlers() {
+		handlers := h.handlers()
+		// The users are resolved once for each distinct set of decorations, and the
+		// result is shared by all handlers requesting the same decorations.
+		resolvers := make(map[string]func() ([]User, error))
+		for _, handler := range handlers {
+			if !resolvesUsers(handler) {
+				continue
+			}
+			decorations := userDecorations(handler)
+			key := joinDecorations(decorations)
+			if _, ok := resolvers[key]; ok {
+				continue
+			}
+			resolve := sync.OnceValues(func() ([]User, error) {
+				return h.resolveUsers(data.XUIDs, decorations)
+			})
+			resolvers[key] = resolve
+			go resolve()
+		}
+		for _, handler := range handlers {
 			xuids := slices.Clone(data.XUIDs)
-			go handler.HandleSocialNotification(data.Type, xuids)
+			var resolve func() ([]User, error)
+			if resolvesUsers(handler) {
+				resolve = resolvers[joinDecorations(userDecorations(handler))]
+			}
+			go func() {
+				handler.HandleSocialNotification(data.Type, xuids)
+				switch handler := handler.(type) {
+				case usersHandler:
+					users, err := resolve()
+					handler.handleUsers(data.Type, xuids, slices.Clone(users), err)
+				case UserSubscriptionHandler:
+					if users, err := resolve(); err == nil {
+						handler.HandleUsers(data.Type, slices.Clone(users))
+					}
+				}
+			}()
 		}
 	default:
 		h.log.Warn("unexpected subscription notification type",
@@ -108,6 +181,45 @@ func (h *subscriptionHandler) HandleEvent(custom json.RawMessage) {
 	}
 }
 
+// resolveUsers resolves the XUIDs to users using [Client.PeopleByXUIDs] for the handlers
+// that receive the users affected by a change. If decorations is empty, the default
+// decorations are requested.
+func (h *subscriptionHandler) resolveUsers(xuids []string, decorations []Decoration) ([]User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+	users, err := h.PeopleByXUIDs(ctx, xuids, PeopleListConfig{Decorations: decorations})
+	if err != nil {
+		h.log.Error("error resolving users of subscription event", "err", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// resolvesUsers reports whether the users affected by a change are resolved for the handler.
+func resolvesUsers(handler SubscriptionHandler) bool {
+	switch handler.(type) {
+	case usersHandler, UserSubscriptionHandler:
+		return true
+	default:
+		return false
+	}
+}
+
+// userDecorations returns the decorations requested when resolving the users for the
+// handler. It returns nil for the default decorations.
+func userDecorations(handler SubscriptionHandler) []Decoration {
+	if handler, ok := handler.(interface{ UserDecorations() []Decoration }); ok {
+		return handler.UserDecorations()
+	}
+	return nil
+}
+
+// usersHandler is implemented by internal handlers that receive the users affected by
+// a change together with their XUIDs, and the error if they could not be resolved.
+type usersHandler interface {
+	handleUsers(typ string, xuids []string, users []User, err error)
+}
+
 func (h *subscriptionHandler) HandleError(err error) {
 	if errors.Is(err, rta.ErrUnsubscribed) {
 		return
@@ -119,10 +231,19 @@ func (h *subscriptionHandler) HandleError(err error) {
 	}
 }
 
+// handlers returns the handlers registered via [Client.Subscribe], unwrapped so that
+// optional interfaces such as [UserSubscriptionHandler] can be detected.
 func (h *subscriptionHandler) handlers() []SubscriptionHandler {
 	h.subscriptionMu.RLock()
 	defer h.subscriptionMu.RUnlock()
-	return slices.Clone(h.subscriptionHandlers)
+	handlers := make([]SubscriptionHandler, len(h.subscriptionHandlers))
+	for i, handler := range h.subscriptionHandlers {
+		if r, ok := handler.(*registeredHandler); ok {
+			handler = r.SubscriptionHandler
+		}
+		handlers[i] = handler
+	}
+	return handlers
 }
 
 // SubscriptionHandler is the interface for receiving real-time notifications
@@ -135,7 +256,7 @@ type SubscriptionHandler interface {
 	// HandleSocialNotification is called when a change in the caller's friend
 	// list is delivered via the RTA subscription.
 	//
-	// typ is one of the NotificationType* constants defined below.
+	// typ is one of [NotificationTypeAdded], [NotificationTypeRemoved] or [NotificationTypeChanged].
 	// xuids lists the XUIDs of the users affected by the change.
 	HandleSocialNotification(typ string, xuids []string)
 
@@ -159,6 +280,31 @@ func (NopSubscriptionHandler) HandleSocialNotification(string, []string)  {}
 func (NopSubscriptionHandler) HandleIncomingFriendRequestCountChange(int) {}
 func (NopSubscriptionHandler) HandleSubscriptionLost()                    {}
 
+// UserSubscriptionHandler may be implemented by a [SubscriptionHandler] registered via
+// [Client.Subscribe] to receive the users affected by a change in the caller's friend list,
+// instead of only their XUIDs. The XUIDs are resolved once per change using a single
+// [Client.PeopleByXUIDs] call shared by all handlers requesting the same decorations.
+type UserSubscriptionHandler interface {
+	SubscriptionHandler
+
+	// HandleUsers is called once the users affected by the change have been resolved, after
+	// [SubscriptionHandler.HandleSocialNotification] for the same change has returned. typ is
+	// the same notification type. HandleUsers is not called if the users could not be resolved.
+	// As with other methods, calls for different changes may run concurrently.
+	HandleUsers(typ string, users []User)
+}
+
+// DecoratedUserSubscriptionHandler may be implemented by a [UserSubscriptionHandler] to select
+// the decorations requested for the users passed to [UserSubscriptionHandler.HandleUsers].
+// Otherwise, the decorations returned by [DefaultDecorations] are requested.
+type DecoratedUserSubscriptionHandler interface {
+	UserSubscriptionHandler
+
+	// UserDecorations returns the decorations requested when resolving the users affected
+	// by a change. If it returns an empty slice, the default decorations are requested.
+	UserDecorations() []Decoration
+}
+
 const (
 	// NotificationTypeAdded is the notification type for when one or more users
 	// add the caller as a friend.
@@ -175,9 +321,15 @@ const (
 	// up to date.
 	NotificationTypeChanged = "Changed"
 
-	// notificationTypeIncomingFriendRequestCountChanged is the notification
+	// NotificationTypeIncomingFriendRequestCountChanged is the notification
 	// type for when the number of pending friend requests sent to the caller
 	// changes.
-	// It is unexported since it is never delivered to the caller.
-	notificationTypeIncomingFriendRequestCountChanged = "IncomingFriendRequestCountChanged"
+	//
+	// It is only reported in [Event.Type]. A [SubscriptionHandler] receives it
+	// through [SubscriptionHandler.HandleIncomingFriendRequestCountChange].
+	NotificationTypeIncomingFriendRequestCountChanged = "IncomingFriendRequestCountChanged"
+
+	// NotificationTypeSubscriptionLost is the type of an [Event] reported when the
+	// underlying subscription is lost. It is never sent by the service.
+	NotificationTypeSubscriptionLost = "SubscriptionLost"
 )
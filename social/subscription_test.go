@@ -7,7 +7,10 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,7 +21,7 @@ import (
 func TestSubscribeWithoutRTAFails(t *testing.T) {
 	client := New(http.DefaultClient, nil, xsts.UserInfo{XUID: "1"}, nil)
 
-	err := client.Subscribe(context.Background(), NopSubscriptionHandler{})
+	_, err := client.Subscribe(context.Background(), NopSubscriptionHandler{})
 	if !errors.Is(err, rta.ErrUnavailable) {
 		t.Fatalf("Subscribe error = %v, want %v", err, rta.ErrUnavailable)
 	}
@@ -113,3 +116,164 @@ func (h nonComparableSocialHandler) HandleIncomingFriendRequestCountChange(int)
 func (h nonComparableSocialHandler) HandleSubscriptionLost() {
 	h.calls <- "lost"
 }
+
+func TestSubscribeResolvesUsersAndCancels(t *testing.T) {
+	var requests atomic.Int32
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"a"},{"xuid":"2","gamertag":"b"}]}`), nil
+	})}, stubProvider{}, xsts.UserInfo{XUID: "0"}, nil)
+	h := &subscriptionHandler{
+		Client: client,
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	calls, users := make(chan string, 4), make(chan []User, 4)
+	cancel, err := client.Subscribe(context.Background(), userSocialHandler{
+		nonComparableSocialHandler: nonComparableSocialHandler{calls: calls, data: []string{"non-comparable"}},
+		users:                      users,
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	events, err := client.Events(ctx, EventsConfig{Users: true})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+
+	h.HandleEvent(json.RawMessage(`{"NotificationType":"Added","Xuids":["1","2"]}`))
+	select {
+	case got := <-calls:
+		if got != "Added:1,2" {
+			t.Fatalf("handler call = %q, want %q", got, "Added:1,2")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+	select {
+	case got := <-users:
+		if len(got) != 2 || got[0].GamerTag != "a" || got[1].GamerTag != "b" {
+			t.Fatalf("users = %+v, want a and b", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("users were not resolved")
+	}
+	select {
+	case e := <-events:
+		if e.Type != NotificationTypeAdded || len(e.Users) != 2 || e.Err != nil {
+			t.Fatalf("event = %+v, want Added with 2 users", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+	// The users are resolved once for both the handler and the events.
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("requests = %d, want 1", n)
+	}
+
+	cancel()
+	stop()
+	for range events {
+	}
+	h.HandleEvent(json.RawMessage(`{"NotificationType":"Removed","Xuids":["1"]}`))
+	select {
+	case got := <-calls:
+		t.Fatalf("handler call = %q after cancel, want none", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if n := len(client.subscriptionHandlers); n != 0 {
+		t.Fatalf("%d handlers registered after cancel, want 0", n)
+	}
+}
+
+func TestSubscribeResolvesUsersPerDecorations(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		paths = append(paths, req.URL.Path)
+		mu.Unlock()
+		return response(req, http.StatusOK, `{"people":[{"xuid":"1","gamertag":"a"}]}`), nil
+	})}, stubProvider{}, xsts.UserInfo{XUID: "0"}, nil)
+	h := &subscriptionHandler{
+		Client: client,
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	var channels []<-chan Event
+	for _, decorations := range [][]Decoration{nil, {DecorationDetail}, {DecorationDetail}} {
+		events, err := client.Events(ctx, EventsConfig{Users: true, Decorations: decorations})
+		if err != nil {
+			t.Fatalf("Events: %v", err)
+		}
+		channels = append(channels, events)
+	}
+
+	h.HandleEvent(json.RawMessage(`{"NotificationType":"Added","Xuids":["1"]}`))
+	for _, events := range channels {
+		select {
+		case e := <-events:
+			if len(e.Users) != 1 || e.Err != nil {
+				t.Fatalf("event = %+v, want 1 user", e)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("event was not delivered")
+		}
+	}
+	// The users are resolved once for each distinct set of decorations.
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(paths)
+	want := []string{
+		"/users/me/people/batch/decoration/" + joinDecorations(DefaultDecorations()),
+		"/users/me/people/batch/decoration/detail",
+	}
+	slices.Sort(want)
+	if !slices.Equal(paths, want) {
+		t.Fatalf("paths = %q, want %q", paths, want)
+	}
+}
+
+type userSocialHandler struct {
+	nonComparableSocialHandler
+	users chan<- []User
+}
+
+func (h userSocialHandler) HandleUsers(_ string, users []User) {
+	h.users <- users
+}
+
+func TestEventsQueuesEventsInOrder(t *testing.T) {
+	client := New(&http.Client{}, stubProvider{}, xsts.UserInfo{XUID: "0"}, nil)
+	h := &subscriptionHandler{
+		Client: client,
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	events, err := client.Events(ctx, EventsConfig{Buffer: 1})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+
+	// The events are handled without blocking while nothing receives from the channel.
+	handler := h.handlers()[0]
+	for i := range 8 {
+		handler.HandleIncomingFriendRequestCountChange(i)
+	}
+	for i := range 8 {
+		select {
+		case e := <-events:
+			if e.Count != i {
+				t.Fatalf("event count = %d, want %d", e.Count, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was not delivered", i)
+		}
+	}
+}
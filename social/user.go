@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,14 +21,28 @@ import (
 // Search returns users whose gamertag or display name matches the given query
 // string. Each returned [User] is only populated with 'detail' and 'preferredColor'
 // decorations as passing other decorations causes an error.
+//
+// Search returns the number of best matching users chosen by the service. Use
+// [Client.SearchPeople] to request a different number of results.
 func (c *Client) Search(ctx context.Context, query string, opts ...internal.RequestOption) ([]User, error) {
+	return c.SearchPeople(ctx, query, 0, opts...)
+}
+
+// SearchPeople returns up to maxItems users whose gamertag or display name matches the
+// given query string, in the same way as [Client.Search]. If maxItems is zero, the number
+// of results is chosen by the service. The results are not paginated, so refine the query
+// to find users that are not included in the results.
+func (c *Client) SearchPeople(ctx context.Context, query string, maxItems int, opts ...internal.RequestOption) ([]User, error) {
 	requestURL := peopleHubEndpoint.JoinPath(
 		"users/me/people/search/decoration/detail,preferredColor",
 	)
-	requestURL.RawQuery = url.Values{
+	values := url.Values{
 		"q": []string{query},
-		// Supported values are: "q" and "maxItems"
-	}.Encode()
+	}
+	if maxItems > 0 {
+		values.Set("maxItems", strconv.Itoa(maxItems))
+	}
+	requestURL.RawQuery = values.Encode()
 
 	req, err := internal.NewRequest(ctx, http.MethodGet, requestURL.String(), nil, append(
 		opts,
@@ -57,9 +73,10 @@ func (c *Client) Search(ctx context.Context, query string, opts ...internal.Requ
 }
 
 // UserByXUID returns the [User] identified by the given XUID. An error is
-// returned if no user with that XUID is found.
+// returned if no user with that XUID is found. Use [Client.PeopleByXUIDs] to
+// customise the decorations requested for the user.
 func (c *Client) UserByXUID(ctx context.Context, xuid string, opts ...internal.RequestOption) (u User, err error) {
-	users, err := c.users(ctx, "me", "xuids("+xuid+")", nil, PeopleListConfig{}, opts)
+	users, err := c.users(ctx, "me", "xuids("+xuid+")", nil, PeopleListConfig{}, nil, opts)
 	if err != nil {
 		return u, err
 	}
@@ -89,9 +106,16 @@ func (c *Client) UserByGamerTag(ctx context.Context, gamertag string, opts ...in
 // UsersByXUIDs returns the [User] profiles for all given XUIDs in a single
 // batch request. The request is sent as a POST to the batch endpoint.
 func (c *Client) UsersByXUIDs(ctx context.Context, xuids []string, opts ...internal.RequestOption) ([]User, error) {
+	return c.PeopleByXUIDs(ctx, xuids, PeopleListConfig{}, opts...)
+}
+
+// PeopleByXUIDs returns the [User] profiles for all given XUIDs in a single batch
+// request. It behaves like [Client.UsersByXUIDs], but the fetch can be customised
+// with a [PeopleListConfig]. [PeopleListConfig.PageSize] is ignored.
+func (c *Client) PeopleByXUIDs(ctx context.Context, xuids []string, conf PeopleListConfig, opts ...internal.RequestOption) ([]User, error) {
 	return c.users(ctx, "me", "batch", batchRequest{
 		XUIDs: xuids,
-	}, PeopleListConfig{}, opts)
+	}, conf, nil, opts)
 }
 
 // Friends returns the caller's friend list. Pending requests that have not
@@ -99,54 +123,66 @@ func (c *Client) UsersByXUIDs(ctx context.Context, xuids []string, opts ...inter
 // retrieve requests sent to the caller, or [Client.OutgoingFriendRequests] to
 // retrieve requests sent by the caller.
 //
-// Use [Client.FriendsOf] to retrieve the friend list of another user.
+// Use [Client.FriendsOf] to retrieve the friend list of another user, or
+// [Client.People] with [PeopleListFriends] to customise the fetch.
 func (c *Client) Friends(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "friends", nil, PeopleListConfig{}, opts)
+	return c.users(ctx, "me", "friends", nil, PeopleListConfig{}, nil, opts)
 }
 
 // Followers returns users who follow the caller. Unlike [Client.Friends], this
 // includes one-way relationships that may not have been accepted as mutual
-// friends.
+// friends. Use [Client.People] with [PeopleListFollowers] to customise the fetch.
 func (c *Client) Followers(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "followers", nil, PeopleListConfig{}, opts)
+	return c.users(ctx, "me", "followers", nil, PeopleListConfig{}, nil, opts)
 }
 
 // Following returns users the caller follows. Unlike [Client.Friends], this
 // includes one-way relationships that may not have been accepted as mutual
-// friends.
+// friends. Use [Client.People] with [PeopleListFollowing] to customise the fetch.
 func (c *Client) Following(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "social", nil, PeopleListConfig{}, opts)
+	return c.users(ctx, "me", "social", nil, PeopleListConfig{}, nil, opts)
+}
+
+// Favorites returns users the caller has added to their favorites using [Client.Favorite].
+// Use [Client.People] with [PeopleListFavorites] to customise the fetch.
+func (c *Client) Favorites(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
+	return c.users(ctx, "me", "favorites", nil, PeopleListConfig{}, nil, opts)
 }
 
 // FriendsOf returns the friend list of the user identified by the given XUID.
 // This can be used to retrieve the friend list of any user, not just the caller.
 // See [Client.Friends] for details on how Xbox Live friend relationships work.
+// Use [Client.PeopleOf] with [PeopleListFriends] to customise the fetch.
 func (c *Client) FriendsOf(ctx context.Context, xuid string, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "xuid("+xuid+")", "friends", nil, PeopleListConfig{}, opts)
+	return c.PeopleOf(ctx, xuid, PeopleListFriends, PeopleListConfig{}, opts...)
 }
 
 // IncomingFriendRequests returns the list of users who have sent the caller a
-// pending friend request.
+// pending friend request. Use [Client.People] with [PeopleListIncomingFriendRequests]
+// to customise the fetch.
 func (c *Client) IncomingFriendRequests(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "friendRequests(received)", nil, PeopleListConfig{}, opts)
+	return c.users(ctx, "me", "friendRequests(received)", nil, PeopleListConfig{}, nil, opts)
 }
 
 // OutgoingFriendRequests returns the list of users to whom the caller has sent
-// a pending friend request.
+// a pending friend request. Use [Client.People] with [PeopleListOutgoingFriendRequests]
+// to customise the fetch.
 func (c *Client) OutgoingFriendRequests(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "friendRequests(sent)", nil, PeopleListConfig{}, opts)
+	return c.users(ctx, "me", "friendRequests(sent)", nil, PeopleListConfig{}, nil, opts)
 }
 
 // Recommendations returns the list of users recommended to the caller by Xbox
 // Live. These correspond to the "Suggested Friends" section in the social
 // widget and are primarily composed of friends of the caller's existing friends.
+// Use [Client.People] with [PeopleListRecommendations] to customise the fetch.
 func (c *Client) Recommendations(ctx context.Context, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "recommendations", nil, PeopleListConfig{}, opts)
+	return c.users(ctx, "me", "recommendations", nil, PeopleListConfig{}, nil, opts)
 }
 
 // PlayedTitle returns the list of friends who have played the title identified by the given ID.
+// Use [Client.People] with [PeopleListPlayedTitle] to customise the fetch.
 func (c *Client) PlayedTitle(ctx context.Context, titleID string, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", "playedTitle("+titleID+")", nil, PeopleListConfig{}, opts)
+	return c.People(ctx, PeopleListPlayedTitle(titleID), PeopleListConfig{}, opts...)
 }
 
 // PeopleList identifies a people group that can be listed from the PeopleHub
@@ -160,8 +196,15 @@ const (
 	PeopleListIncomingFriendRequests PeopleList = "friendRequests(received)"
 	PeopleListOutgoingFriendRequests PeopleList = "friendRequests(sent)"
 	PeopleListRecommendations        PeopleList = "recommendations"
+	PeopleListFavorites              PeopleList = "favorites"
 )
 
+// PeopleListPlayedTitle returns the [PeopleList] of friends who have played the
+// title identified by the given ID.
+func PeopleListPlayedTitle(titleID string) PeopleList {
+	return PeopleList("playedTitle(" + titleID + ")")
+}
+
 // PeopleListConfig customises how a people list is retrieved from PeopleHub.
 type PeopleListConfig struct {
 	// Undecorated requests the list without any profile decorations. Only the
@@ -174,21 +217,185 @@ type PeopleListConfig struct {
 	// the request. If zero, the default version 7 is used. Undecorated list
 	// polling is typically served with version 5.
 	ContractVersion int
+	// PageSize is the maximum number of users requested in each page by the
+	// iterator methods, such as [Client.AllPeople]. If zero, 100 is used. It
+	// is ignored by methods returning the whole list in a single response.
+	PageSize int
+	// Decorations lists the decorations requested for each user in the list, so that
+	// callers only pay for the fields they use. If empty, the decorations returned by
+	// [DefaultDecorations] are requested. It is ignored if Undecorated is true.
+	Decorations []Decoration
+}
+
+// Decoration identifies an optional part of the user profiles returned by PeopleHub.
+// Decorations are requested with [PeopleListConfig.Decorations], and each decoration
+// populates the fields of [User] documented to depend on it.
+type Decoration string
+
+const (
+	// DecorationBio populates the bio of the user in [UserDetail.Bio].
+	DecorationBio Decoration = "bio"
+	// DecorationDetail populates [User.Detail].
+	DecorationDetail Decoration = "detail"
+	// DecorationMultiplayerSummary populates [User.MultiplayerSummary].
+	DecorationMultiplayerSummary Decoration = "multiplayerSummary"
+	// DecorationPreferredColor populates [User.PreferredColor].
+	DecorationPreferredColor Decoration = "preferredColor"
+	// DecorationPresenceDetail populates [User.PresenceDetails].
+	DecorationPresenceDetail Decoration = "presenceDetail"
+	// DecorationPresenceTitleIDs populates [User.PresenceTitleIDs].
+	DecorationPresenceTitleIDs Decoration = "presenceTitleIds"
+	// DecorationTitlePresence populates [User.TitlePresence] for the title of the caller.
+	DecorationTitlePresence Decoration = "titlePresence"
+	// DecorationTitleSummary populates [User.TitleSummaries].
+	DecorationTitleSummary Decoration = "titleSummary"
+	// DecorationTitleHistory populates [User.TitleHistory].
+	DecorationTitleHistory Decoration = "titleHistory"
+	// DecorationCommunityManagerTitles populates [User.CommunityManagerTitles].
+	DecorationCommunityManagerTitles Decoration = "communityManagerTitles"
+	// DecorationSocialManager populates [User.SocialManager].
+	DecorationSocialManager Decoration = "socialManager"
+	// DecorationBroadcast populates [User.Broadcast].
+	DecorationBroadcast Decoration = "broadcast"
+	// DecorationTournamentSummary populates [User.TournamentSummary].
+	DecorationTournamentSummary Decoration = "tournamentSummary"
+	// DecorationAvatar populates [User.Avatar].
+	DecorationAvatar Decoration = "avatar"
+)
+
+// DefaultDecorations returns the decorations requested by the methods implemented on
+// Client unless others are specified with [PeopleListConfig.Decorations]. The returned
+// slice is a copy and may be modified by the caller.
+func DefaultDecorations() []Decoration {
+	return slices.Clone(defaultDecorations)
+}
+
+// defaultDecorations holds the decorations returned by [DefaultDecorations].
+var defaultDecorations = []Decoration{
+	DecorationBio,
+	DecorationDetail,
+	DecorationMultiplayerSummary,
+	DecorationPreferredColor,
+	DecorationPresenceDetail,
+}
+
+// joinDecorations joins the decorations into a comma-separated list.
+func joinDecorations(decorations []Decoration) string {
+	s := make([]string, len(decorations))
+	for i, decoration := range decorations {
+		s[i] = string(decoration)
+	}
+	return strings.Join(s, ",")
 }
 
 // People returns the users in the given people list. It behaves like the
 // corresponding convenience method ([Client.Followers], [Client.Following],
 // ...), but the fetch can be customised with a [PeopleListConfig].
 func (c *Client) People(ctx context.Context, list PeopleList, conf PeopleListConfig, opts ...internal.RequestOption) ([]User, error) {
-	return c.users(ctx, "me", string(list), nil, conf, opts)
+	return c.users(ctx, "me", string(list), nil, conf, nil, opts)
+}
+
+// PeopleOf returns the users in the given people list of the user identified by
+// the XUID. It behaves like [Client.FriendsOf], but other lists may be requested
+// and the fetch can be customised with a [PeopleListConfig]. Most lists other
+// than [PeopleListFriends] are only accessible for the caller.
+func (c *Client) PeopleOf(ctx context.Context, xuid string, list PeopleList, conf PeopleListConfig, opts ...internal.RequestOption) ([]User, error) {
+	return c.users(ctx, "xuid("+xuid+")", string(list), nil, conf, nil, opts)
 }
 
+// AllPeople returns an iterator over the users in the given people list. Unlike
+// [Client.People], the list is retrieved lazily in pages of [PeopleListConfig.PageSize]
+// users, so lists that are too large to be returned in a single response are not truncated.
+// The next page is only requested once the users of the previous page have been consumed,
+// and no more requests are made once the caller stops the iteration. Pages are requested
+// until the service returns an empty page, so a [PeopleListConfig.PageSize] above the
+// limit of the service for a single page does not truncate the list.
+//
+// If a page cannot be retrieved, the error is yielded and the iteration stops.
+func (c *Client) AllPeople(ctx context.Context, list PeopleList, conf PeopleListConfig, opts ...internal.RequestOption) iter.Seq2[User, error] {
+	return c.allUsers(ctx, "me", string(list), conf, opts)
+}
+
+// AllPeopleOf returns an iterator over the users in the given people list of the user
+// identified by the XUID. It is the paginated variant of [Client.PeopleOf]; see
+// [Client.AllPeople] for details on pagination.
+func (c *Client) AllPeopleOf(ctx context.Context, xuid string, list PeopleList, conf PeopleListConfig, opts ...internal.RequestOption) iter.Seq2[User, error] {
+	return c.allUsers(ctx, "xuid("+xuid+")", string(list), conf, opts)
+}
+
+// AllFriends returns an iterator over the caller's friend list. It is the paginated
+// variant of [Client.Friends]; see [Client.AllPeople] for details on pagination.
+func (c *Client) AllFriends(ctx context.Context, conf PeopleListConfig, opts ...internal.RequestOption) iter.Seq2[User, error] {
+	return c.allUsers(ctx, "me", "friends", conf, opts)
+}
+
+// AllFollowers returns an iterator over users who follow the caller. It is the paginated
+// variant of [Client.Followers]; see [Client.AllPeople] for details on pagination.
+func (c *Client) AllFollowers(ctx context.Context, conf PeopleListConfig, opts ...internal.RequestOption) iter.Seq2[User, error] {
+	return c.allUsers(ctx, "me", "followers", conf, opts)
+}
+
+// AllFollowing returns an iterator over users the caller follows. It is the paginated
+// variant of [Client.Following]; see [Client.AllPeople] for details on pagination.
+func (c *Client) AllFollowing(ctx context.Context, conf PeopleListConfig, opts ...internal.RequestOption) iter.Seq2[User, error] {
+	return c.allUsers(ctx, "me", "social", conf, opts)
+}
+
+// allUsers returns an iterator over the users in the people group identified by the
+// selector from the perspective, requesting each page using the startIndex and maxItems
+// query parameters. The service may return fewer users than requested in a page that is
+// not the last one, such as when maxItems exceeds its own limit, so each page starts after
+// the users returned so far, and the iteration only ends once an empty page is returned.
+//
+// People groups that do not support pagination return the whole list regardless of the
+// query parameters, so the iteration also ends once a page contains more users than
+// requested, or only users that have already been yielded. Each user is yielded at most once.
+func (c *Client) allUsers(ctx context.Context, perspective, selector string, conf PeopleListConfig, opts []internal.RequestOption) iter.Seq2[User, error] {
+	pageSize := conf.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	return func(yield func(User, error) bool) {
+		seen := make(map[string]struct{})
+		for start := 0; ; {
+			users, err := c.users(ctx, perspective, selector, nil, conf, url.Values{
+				"startIndex": []string{strconv.Itoa(start)},
+				"maxItems":   []string{strconv.Itoa(pageSize)},
+			}, opts)
+			if err != nil {
+				yield(User{}, fmt.Errorf("xsapi/social: retrieve page at %d: %w", start, err))
+				return
+			}
+			unseen := 0
+			for _, user := range users {
+				if _, ok := seen[user.XUID]; ok {
+					continue
+				}
+				seen[user.XUID] = struct{}{}
+				unseen++
+				if !yield(user, nil) {
+					return
+				}
+			}
+			if unseen == 0 || len(users) > pageSize {
+				return
+			}
+			start += len(users)
+		}
+	}
+}
+
+// defaultPageSize is the number of users requested in each page by
+// [Client.AllPeople] if [PeopleListConfig.PageSize] is zero.
+const defaultPageSize = 100
+
 // users is the shared implementation for querying multiple users via the
 // PeopleHub API. perspective corresponds to the "owner" field in the Xbox Live
 // API, and selector corresponds to the "people group". If postBody is non-nil,
 // the request is sent as a POST with postBody JSON-encoded in the request body.
-// Otherwise, a GET request is made.
-func (c *Client) users(ctx context.Context, perspective, selector string, postBody any, conf PeopleListConfig, opts []internal.RequestOption) ([]User, error) {
+// Otherwise, a GET request is made. If query is non-nil, it is encoded as
+// the query of the request URL.
+func (c *Client) users(ctx context.Context, perspective, selector string, postBody any, conf PeopleListConfig, query url.Values, opts []internal.RequestOption) ([]User, error) {
 	segments := []string{
 		"users",
 		perspective,
@@ -196,14 +403,22 @@ func (c *Client) users(ctx context.Context, perspective, selector string, postBo
 		selector,
 	}
 	if !conf.Undecorated {
-		segments = append(segments, "decoration", decorations)
+		decorations := conf.Decorations
+		if len(decorations) == 0 {
+			decorations = defaultDecorations
+		}
+		segments = append(segments, "decoration", joinDecorations(decorations))
 	}
 	contractVersion := peopleHubContractVersion
 	if conf.ContractVersion > 0 {
 		contractVersion = internal.ContractVersion(strconv.Itoa(conf.ContractVersion))
 	}
+	u := peopleHubEndpoint.JoinPath(segments...)
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
 	var (
-		requestURL = peopleHubEndpoint.JoinPath(segments...).String()
+		requestURL = u.String()
 
 		reqBody io.Reader
 		method  string
@@ -278,16 +493,6 @@ var (
 	// peopleHubContractVersion is an [internal.RequestOption] that sets an 'X-Xbl-Contract-Version'
 	// header to '7' for requests made to the peopleHubEndpoint.
 	peopleHubContractVersion = internal.ContractVersion("7")
-
-	// decorations includes a full set of decorations included in the user profiles
-	// returned by the methods implemented on Client.
-	decorations = strings.Join([]string{
-		"bio",
-		"detail",
-		"multiplayerSummary",
-		"preferredColor",
-		"presenceDetail",
-	}, ",")
 )
 
 // User represents a single user profile in Xbox Live.
@@ -398,8 +603,31 @@ type User struct {
 	// This value increases as the user unlocks achievements.
 	GamerScore json.Number `json:"gamerScore,omitempty"`
 	// TitleHistory is a struct containing the history of titles played by the user.
-	// It is almost nil, but is occasionally populated in the user profile.
+	// It is almost nil, but is occasionally populated in the user profile, and is
+	// requested with [DecorationTitleHistory].
 	TitleHistory *UserTitleHistory `json:"titleHistory,omitempty"`
+	// TitlePresence describes the presence of the user in the title of the caller.
+	// It is only populated when [DecorationTitlePresence] is requested.
+	TitlePresence *UserTitlePresence `json:"titlePresence,omitempty"`
+	// PresenceTitleIDs lists the IDs of the titles the user is currently present in.
+	// It is only populated when [DecorationPresenceTitleIDs] is requested.
+	PresenceTitleIDs []string `json:"presenceTitleIds,omitempty"`
+	// MultiplayerSummary summarises the multiplayer activities of the user. It is
+	// only populated when [DecorationMultiplayerSummary] is requested.
+	MultiplayerSummary *UserMultiplayerSummary `json:"multiplayerSummary,omitempty"`
+	// SocialManager holds the titles and pages in which the user is tracked by the social
+	// manager. It is only populated when [DecorationSocialManager] is requested.
+	SocialManager *UserSocialManager `json:"socialManager,omitempty"`
+
+	// TitleSummaries, CommunityManagerTitles, Broadcast, TournamentSummary and Avatar hold
+	// the undocumented values returned for [DecorationTitleSummary], [DecorationCommunityManagerTitles],
+	// [DecorationBroadcast], [DecorationTournamentSummary] and [DecorationAvatar] respectively.
+	// They are usually null, and are kept in their raw form since their structure is unknown.
+	TitleSummaries         json.RawMessage `json:"titleSummaries,omitempty"`
+	CommunityManagerTitles json.RawMessage `json:"communityManagerTitles,omitempty"`
+	Broadcast              json.RawMessage `json:"broadcast,omitempty"`
+	TournamentSummary      json.RawMessage `json:"tournamentSummary,omitempty"`
+	Avatar                 json.RawMessage `json:"avatar,omitempty"`
 
 	// Detail describes the details of the user. It is only populated
 	// when "detail" is included in the decorations of the query.
@@ -664,6 +892,38 @@ type UserTitleHistory struct {
 	LastTimePlayedText string `json:"lastTimePlayedText"`
 }
 
+// UserTitlePresence describes the presence of a user in the title of the caller.
+type UserTitlePresence struct {
+	// CurrentlyPlaying reports whether the user is currently playing the title.
+	CurrentlyPlaying bool `json:"IsCurrentlyPlaying"`
+	// PresenceText is a localized, user-facing value describing the presence of the user in the title.
+	PresenceText string `json:"PresenceText,omitempty"`
+	// TitleName is the name of the title.
+	TitleName string `json:"TitleName,omitempty"`
+	// TitleID is the ID of the title.
+	TitleID string `json:"TitleId,omitempty"`
+}
+
+// UserMultiplayerSummary summarises the multiplayer activities of a user.
+type UserMultiplayerSummary struct {
+	// InParty is the number of parties the user is in.
+	InParty int `json:"InParty"`
+	// InMultiplayerSession is the number of multiplayer sessions the user is in.
+	InMultiplayerSession int `json:"InMultiplayerSession"`
+	// JoinableActivities lists the joinable activities of the user in their raw form.
+	JoinableActivities []json.RawMessage `json:"joinableActivities,omitempty"`
+	// PartyDetails lists the details of the parties of the user in their raw form.
+	PartyDetails []json.RawMessage `json:"partyDetails,omitempty"`
+}
+
+// UserSocialManager holds the titles and pages in which a user is tracked by the social manager.
+type UserSocialManager struct {
+	// TitleIDs lists the IDs of the titles.
+	TitleIDs []string `json:"titleIds"`
+	// Pages lists the pages.
+	Pages []string `json:"pages"`
+}
+
 // UserPreferredColor represents the profile color set by the user.
 type UserPreferredColor struct {
 	// ColorURI is the URL to a JSON file that describes this color.
@@ -0,0 +1,161 @@
+package social
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+func TestAllPeoplePagesLazily(t *testing.T) {
+	var starts []string
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		if got := query.Get("maxItems"); got != "2" {
+			t.Errorf("maxItems = %q, want %q", got, "2")
+		}
+		starts = append(starts, query.Get("startIndex"))
+		start, err := strconv.Atoi(query.Get("startIndex"))
+		if err != nil {
+			return nil, err
+		}
+		// The list contains 5 users.
+		var people []string
+		for i := start; i < min(start+2, 5); i++ {
+			people = append(people, fmt.Sprintf(`{"xuid":"%d"}`, i))
+		}
+		return response(req, http.StatusOK, `{"people":[`+strings.Join(people, ",")+`]}`), nil
+	})}, nil, xsts.UserInfo{}, nil)
+
+	var xuids []string
+	for user, err := range client.AllFollowers(context.Background(), PeopleListConfig{PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("AllFollowers: %v", err)
+		}
+		xuids = append(xuids, user.XUID)
+	}
+	if got := strings.Join(xuids, ","); got != "0,1,2,3,4" {
+		t.Fatalf("XUIDs = %s, want 0,1,2,3,4", got)
+	}
+	// The iteration ends once an empty page is returned.
+	if got := strings.Join(starts, ","); got != "0,2,4,5" {
+		t.Fatalf("start indices = %s, want 0,2,4,5", got)
+	}
+
+	// No more pages are requested once the caller stops the iteration.
+	starts = nil
+	for range client.AllFollowers(context.Background(), PeopleListConfig{PageSize: 2}) {
+		break
+	}
+	if len(starts) != 1 {
+		t.Fatalf("requested %d pages after break, want 1", len(starts))
+	}
+}
+
+func TestAllPeopleContinuesAfterCappedPages(t *testing.T) {
+	var starts []string
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		starts = append(starts, req.URL.Query().Get("startIndex"))
+		start, err := strconv.Atoi(req.URL.Query().Get("startIndex"))
+		if err != nil {
+			return nil, err
+		}
+		// The list contains 5 users, and the service caps each page at 2 users.
+		var people []string
+		for i := start; i < min(start+2, 5); i++ {
+			people = append(people, fmt.Sprintf(`{"xuid":"%d"}`, i))
+		}
+		return response(req, http.StatusOK, `{"people":[`+strings.Join(people, ",")+`]}`), nil
+	})}, nil, xsts.UserInfo{}, nil)
+
+	var xuids []string
+	for user, err := range client.AllPeopleOf(context.Background(), "1", PeopleListFriends, PeopleListConfig{PageSize: 10}) {
+		if err != nil {
+			t.Fatalf("AllPeopleOf: %v", err)
+		}
+		xuids = append(xuids, user.XUID)
+	}
+	if got := strings.Join(xuids, ","); got != "0,1,2,3,4" {
+		t.Fatalf("XUIDs = %s, want 0,1,2,3,4", got)
+	}
+	if got := strings.Join(starts, ","); got != "0,2,4,5" {
+		t.Fatalf("start indices = %s, want 0,2,4,5", got)
+	}
+}
+
+func TestAllPeopleStopsWhenPagingIsIgnored(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		people string
+		want   string
+	}{
+		{name: "page larger than requested", people: `{"xuid":"0"},{"xuid":"1"},{"xuid":"2"}`, want: "0,1,2"},
+		{name: "page repeats users", people: `{"xuid":"0"},{"xuid":"1"}`, want: "0,1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				requests++
+				if requests > 2 {
+					return nil, fmt.Errorf("requested %d pages", requests)
+				}
+				// The list ignores startIndex and maxItems.
+				return response(req, http.StatusOK, `{"people":[`+tt.people+`]}`), nil
+			})}, nil, xsts.UserInfo{}, nil)
+
+			var xuids []string
+			for user, err := range client.AllFollowers(context.Background(), PeopleListConfig{PageSize: 2}) {
+				if err != nil {
+					t.Fatalf("AllFollowers: %v", err)
+				}
+				xuids = append(xuids, user.XUID)
+			}
+			if got := strings.Join(xuids, ","); got != tt.want {
+				t.Fatalf("XUIDs = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeopleRequestsConfiguredDecorations(t *testing.T) {
+	var paths []string
+	client := New(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		return response(req, http.StatusOK, `{"people":[{"xuid":"1",
+			"titleHistory":{"lastTimePlayed":"2024-01-02T03:04:05Z","lastTimePlayedText":"1d ago"},
+			"multiplayerSummary":{"InParty":1,"InMultiplayerSession":2},
+			"tournamentSummary":{"tournaments":[]}}]}`), nil
+	})}, nil, xsts.UserInfo{XUID: "0"}, nil)
+
+	users, err := client.People(context.Background(), PeopleListFriends, PeopleListConfig{
+		Decorations: []Decoration{DecorationTitleHistory, DecorationMultiplayerSummary, DecorationTournamentSummary},
+	})
+	if err != nil {
+		t.Fatalf("People: %v", err)
+	}
+	if _, err := client.People(context.Background(), PeopleListFriends, PeopleListConfig{}); err != nil {
+		t.Fatalf("People: %v", err)
+	}
+	want := []string{
+		"/users/me/people/friends/decoration/titleHistory,multiplayerSummary,tournamentSummary",
+		"/users/me/people/friends/decoration/" + joinDecorations(DefaultDecorations()),
+	}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+
+	u := users[0]
+	if u.TitleHistory == nil || u.TitleHistory.LastTimePlayedText != "1d ago" {
+		t.Fatalf("TitleHistory = %+v", u.TitleHistory)
+	}
+	if u.MultiplayerSummary == nil || u.MultiplayerSummary.InParty != 1 || u.MultiplayerSummary.InMultiplayerSession != 2 {
+		t.Fatalf("MultiplayerSummary = %+v", u.MultiplayerSummary)
+	}
+	if string(u.TournamentSummary) != `{"tournaments":[]}` {
+		t.Fatalf("TournamentSummary = %s", u.TournamentSummary)
+	}
+}